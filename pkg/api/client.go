@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,6 +18,10 @@ const (
 	downloadEndpoint      = `https://download.toontownrewritten.com/patches/`
 )
 
+// ErrTwoFactorRejected is returned by CompleteTwoFactorAuth when the code was
+// not accepted.
+var ErrTwoFactorRejected = errors.New("two-factor auth code rejected")
+
 type SuccessKind string
 
 const (
@@ -89,24 +94,64 @@ type DownloadClient interface {
 	DownloadFile(ctx context.Context, name string) (io.ReadCloser, error)
 }
 
+// InfoClient accesses the public game information endpoints, which do not
+// require logging in.
+type InfoClient interface {
+	Status(ctx context.Context) (StatusSpec, error)
+	Invasions(ctx context.Context) (*InvasionsSpec, error)
+	Population(ctx context.Context) (*PopulationSpec, error)
+	FieldOffices(ctx context.Context) (*FieldOfficesSpec, error)
+	LatestNews(ctx context.Context) (*NewsPost, error)
+	NewsList(ctx context.Context) ([]*NewsPost, error)
+	NewsPost(ctx context.Context, id int) (*NewsPost, error)
+	ReleaseNotes(ctx context.Context) ([]*ReleaseNote, error)
+	ReleaseNote(ctx context.Context, id int) (*ReleaseNote, error)
+	SillyMeter(ctx context.Context) (*SillyMeterSpec, error)
+}
+
 type Client interface {
 	LoginClient
 	DownloadClient
-
-	Status(ctx context.Context) (StatusSpec, error)
+	InfoClient
 }
 
 type client struct {
-	httpClient *http.Client
+	httpClient  *http.Client
+	apiEndpoint string
+}
+
+type ClientOptions struct {
+	apiEndpoint string
+}
+
+type ClientOption func(*ClientOptions)
+
+func (o *ClientOptions) apply(opts ...ClientOption) {
+	for _, op := range opts {
+		op(o)
+	}
+}
+
+// WithAPIEndpoint overrides the base URL of the API, e.g. to use a test server.
+func WithAPIEndpoint(endpoint string) ClientOption {
+	return func(o *ClientOptions) {
+		o.apiEndpoint = strings.TrimSuffix(endpoint, "/")
+	}
 }
 
-func NewClient() Client {
+func NewClient(opts ...ClientOption) Client {
+	options := ClientOptions{
+		apiEndpoint: apiEndpoint,
+	}
+	options.apply(opts...)
+
 	return &client{
 		httpClient: &http.Client{
 			Transport: &http.Transport{
 				TLSClientConfig: &tls.Config{},
 			},
 		},
+		apiEndpoint: options.apiEndpoint,
 	}
 }
 
@@ -114,7 +159,7 @@ func (c *client) Login(ctx context.Context, username, password string) (*LoginRe
 	form := url.Values{}
 	form.Add("username", username)
 	form.Add("password", password)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiEndpoint+"/login?format=json", strings.NewReader(form.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiEndpoint+"/login?format=json", strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, err
 	}
@@ -142,7 +187,7 @@ func (c *client) Login(ctx context.Context, username, password string) (*LoginRe
 func (c *client) RetryDelayedLogin(ctx context.Context, queueToken string) (*LoginResponse, error) {
 	form := url.Values{}
 	form.Add("queueToken", queueToken)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiEndpoint+"/login?format=json", strings.NewReader(form.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiEndpoint+"/login?format=json", strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, err
 	}
@@ -171,7 +216,7 @@ func (c *client) CompleteTwoFactorAuth(ctx context.Context, responseToken, code
 	form := url.Values{}
 	form.Add("authToken", responseToken)
 	form.Add("appToken", code)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiEndpoint+"/login?format=json", strings.NewReader(form.Encode()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiEndpoint+"/login?format=json", strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, err
 	}
@@ -193,8 +238,10 @@ func (c *client) CompleteTwoFactorAuth(ctx context.Context, responseToken, code
 	if err := json.Unmarshal(respData, &loginResp); err != nil {
 		return nil, err
 	}
-	if loginResp.Success == "partial" {
-		return nil, fmt.Errorf("API error submitting 2FA code (try logging in to the website once): %s", loginResp.Message)
+	if loginResp.Success == SuccessPartial {
+		// the response is returned as well, since it may contain a new
+		// response token that can be used to try again
+		return &loginResp, fmt.Errorf("%w (try logging in to the website once): %s", ErrTwoFactorRejected, loginResp.Message)
 	}
 	return &loginResp, nil
 }
@@ -234,18 +281,28 @@ func (c *client) DownloadFile(ctx context.Context, name string) (io.ReadCloser,
 }
 
 func (c *client) Status(ctx context.Context) (StatusSpec, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiEndpoint+"/status", nil)
-	if err != nil {
+	var status StatusSpec
+	if err := c.getJSON(ctx, "/status", &status); err != nil {
 		return StatusSpec{}, err
 	}
+	return status, nil
+}
+
+// getJSON fetches one of the public API endpoints and decodes the response.
+func (c *client) getJSON(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiEndpoint+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return StatusSpec{}, err
+		return err
 	}
 	defer resp.Body.Close()
-	var status StatusSpec
-	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
-		return StatusSpec{}, err
+	if resp.StatusCode/100 != 2 {
+		respData, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API error: %s: %s", resp.Status, string(respData))
 	}
-	return status, nil
+	return json.NewDecoder(resp.Body).Decode(out)
 }
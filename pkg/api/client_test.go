@@ -0,0 +1,215 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kralicky/ttr/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client for a server that serves the fixtures in
+// testdata, where a request for /foo/bar is served from testdata/foo_bar.json.
+func newTestClient(t *testing.T) api.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.ReplaceAll(strings.Trim(r.URL.Path, "/"), "/", "_")
+		data, err := os.ReadFile(filepath.Join("testdata", name+".json"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return api.NewClient(api.WithAPIEndpoint(srv.URL))
+}
+
+func TestStatus(t *testing.T) {
+	client := newTestClient(t)
+	status, err := client.Status(context.Background())
+	require.NoError(t, err)
+	assert.False(t, status.Open)
+	assert.Equal(t, "Toontown Rewritten is currently closed for maintenance.", status.Banner)
+	assert.Equal(t, int64(1718034000), status.LastCookieIssuedAt)
+	assert.Equal(t, int64(1718034500), status.LastGameAuthAt)
+}
+
+func TestInvasions(t *testing.T) {
+	client := newTestClient(t)
+	spec, err := client.Invasions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, spec.Invasions, 3)
+	assert.Equal(t, int64(1718034781), spec.LastUpdated)
+
+	inv := spec.Invasions["Kaboom Cliffs"]
+	assert.Equal(t, "Telemarketer", inv.CogName())
+	assert.Equal(t, api.Sellbot, inv.Department())
+	assert.Equal(t, api.Progress{Defeated: 2841, Total: 3000}, inv.Progress)
+	assert.Equal(t, 159, inv.Progress.Remaining())
+	assert.Equal(t, int64(1718034780), inv.AsOfTime().Unix())
+
+	inv = spec.Invasions["Splat Summit"]
+	assert.Equal(t, api.Lawbot, inv.Department())
+	assert.Equal(t, 6880, inv.Progress.Remaining())
+
+	inv = spec.Invasions["Boingy Acres"]
+	assert.Equal(t, api.Bossbot, inv.Department())
+	assert.Equal(t, "4015/5000", inv.Progress.String())
+}
+
+func TestParseDepartment(t *testing.T) {
+	for input, expected := range map[string]api.Department{
+		"s":       api.Sellbot,
+		"Cashbot": api.Cashbot,
+		"LAW":     api.Lawbot,
+		"bossbot": api.Bossbot,
+	} {
+		dept, ok := api.ParseDepartment(input)
+		assert.True(t, ok, input)
+		assert.Equal(t, expected, dept, input)
+	}
+	_, ok := api.ParseDepartment("boardbot")
+	assert.False(t, ok)
+}
+
+func TestPopulation(t *testing.T) {
+	client := newTestClient(t)
+	pop, err := client.Population(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1043, pop.TotalPopulation)
+	assert.Equal(t, 95, pop.PopulationByDistrict["Kaboom Cliffs"])
+
+	inv, err := client.Invasions(context.Background())
+	require.NoError(t, err)
+
+	districts := api.Districts(pop, inv)
+	require.Len(t, districts, 6)
+	assert.Equal(t, "Boingy Acres", districts[0].Name)
+	assert.Equal(t, "The Big Cheese", districts[0].Invasion.CogName())
+	assert.Nil(t, districts[1].Invasion)
+	assert.Equal(t, "Whoosh Rapids", districts[4].Name)
+	assert.False(t, districts[4].Online())
+
+	names := func(ds []api.District) []string {
+		var out []string
+		for _, d := range ds {
+			out = append(out, d.Name)
+		}
+		return out
+	}
+
+	cases := map[string][]string{
+		"least-populated":     {"Gulp Gulch", "Kaboom Cliffs", "Boingy Acres", "Splat Summit", "Zoink Falls"},
+		"invasion":            {"Kaboom Cliffs", "Boingy Acres", "Splat Summit"},
+		"invasion:lawbot":     {"Splat Summit"},
+		"invasion:big cheese": {"Boingy Acres"},
+		"invasion:flunky":     nil,
+	}
+	for input, expected := range cases {
+		pref, err := api.ParseDistrictPreference(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, names(api.RecommendDistricts(districts, pref)), input)
+	}
+	_, err = api.ParseDistrictPreference("most-populated")
+	assert.Error(t, err)
+}
+
+func TestFieldOffices(t *testing.T) {
+	client := newTestClient(t)
+	spec, err := client.FieldOffices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1718034790), spec.LastUpdated)
+
+	list := spec.List()
+	require.Len(t, list, 3)
+
+	assert.Equal(t, int64(3100), list[0].ZoneId)
+	assert.Equal(t, "Walrus Way", list[0].Street)
+	assert.Equal(t, "The Brrrgh", list[0].Hood)
+	assert.Equal(t, 3, list[0].Stars())
+	assert.Equal(t, 11, list[0].Annexes)
+	assert.True(t, list[0].Open)
+	_, expiring := list[0].ExpiresAt()
+	assert.False(t, expiring)
+
+	assert.Equal(t, "Maple Street", list[1].Street)
+	assert.Equal(t, 1, list[1].Stars())
+	assert.False(t, list[1].Open)
+	expiresAt, expiring := list[1].ExpiresAt()
+	assert.True(t, expiring)
+	assert.Equal(t, int64(1718035090), expiresAt.Unix())
+
+	assert.Equal(t, "Lullaby Lane", list[2].Street)
+	assert.Equal(t, "Donald's Dreamland", list[2].Hood)
+}
+
+func TestNews(t *testing.T) {
+	client := newTestClient(t)
+	post, err := client.LatestNews(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 512, post.PostId)
+	assert.Equal(t, "Summer Splashdown Returns!", post.Title)
+	assert.Equal(t, `Toons, the Summer Splashdown is back!
+
+This year you can:
+
+• Earn new beach-themed accessories
+• Visit the Toon HQ (https://www.toontownrewritten.com/play) for details
+
+See you on the beach!
+The TTR Team`, post.Text())
+
+	posts, err := client.NewsList(context.Background())
+	require.NoError(t, err)
+	require.Len(t, posts, 2)
+	assert.Equal(t, 511, posts[1].PostId)
+
+	notes, err := client.ReleaseNotes(context.Background())
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	assert.Equal(t, 88, notes[0].NoteId)
+	assert.Empty(t, notes[0].Body)
+
+	note, err := client.ReleaseNote(context.Background(), 88)
+	require.NoError(t, err)
+	assert.Equal(t, "ttr-beta-v4-5-2", note.Slug)
+	assert.Equal(t, `Bug Fixes
+
+• Fixed a crash when entering the Bullion Mint.
+• Fixed gag track icons overlapping.
+
+Thanks for playing & reporting!`, note.Text())
+
+	_, err = client.ReleaseNote(context.Background(), 1)
+	assert.Error(t, err)
+}
+
+func TestSillyMeter(t *testing.T) {
+	client := newTestClient(t)
+	spec, err := client.SillyMeter(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, api.SillyMeterReward, spec.State)
+	assert.Equal(t, 100.0, spec.Percent())
+	assert.Equal(t, int64(1718038400), spec.NextUpdate().Unix())
+
+	name, desc, ok := spec.ActiveReward()
+	assert.True(t, ok)
+	assert.Equal(t, "Double Jellybeans", name)
+	assert.Equal(t, "Earn double jellybeans from all activities!", desc)
+
+	choices := spec.RewardChoices()
+	require.Len(t, choices, 3)
+	assert.Equal(t, api.SillyMeterChoice{
+		Name:        "Decreased Fish Rarity",
+		Description: "Rare fish are easier to catch!",
+		Points:      1284812,
+	}, choices[2])
+}
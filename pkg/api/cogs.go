@@ -0,0 +1,86 @@
+package api
+
+import (
+	"strings"
+	"unicode"
+)
+
+type Department string
+
+const (
+	Sellbot Department = "Sellbot"
+	Cashbot Department = "Cashbot"
+	Lawbot  Department = "Lawbot"
+	Bossbot Department = "Bossbot"
+	Unknown Department = "Unknown"
+)
+
+var cogDepartments = map[string]Department{
+	"Cold Caller":      Sellbot,
+	"Telemarketer":     Sellbot,
+	"Name Dropper":     Sellbot,
+	"Glad Hander":      Sellbot,
+	"Mover & Shaker":   Sellbot,
+	"Two-Face":         Sellbot,
+	"The Mingler":      Sellbot,
+	"Mr. Hollywood":    Sellbot,
+	"Short Change":     Cashbot,
+	"Penny Pincher":    Cashbot,
+	"Tightwad":         Cashbot,
+	"Bean Counter":     Cashbot,
+	"Number Cruncher":  Cashbot,
+	"Money Bags":       Cashbot,
+	"Loan Shark":       Cashbot,
+	"Robber Baron":     Cashbot,
+	"Bottom Feeder":    Lawbot,
+	"Bloodsucker":      Lawbot,
+	"Double Talker":    Lawbot,
+	"Ambulance Chaser": Lawbot,
+	"Back Stabber":     Lawbot,
+	"Spin Doctor":      Lawbot,
+	"Legal Eagle":      Lawbot,
+	"Big Wig":          Lawbot,
+	"Flunky":           Bossbot,
+	"Pencil Pusher":    Bossbot,
+	"Yesman":           Bossbot,
+	"Micromanager":     Bossbot,
+	"Downsizer":        Bossbot,
+	"Head Hunter":      Bossbot,
+	"Corporate Raider": Bossbot,
+	"The Big Cheese":   Bossbot,
+}
+
+// CleanCogName removes the control characters the API sometimes includes in
+// cog names (e.g. "Tele\u0003marketer").
+func CleanCogName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+}
+
+// CogDepartment returns the department of the named cog.
+func CogDepartment(name string) Department {
+	if dept, ok := cogDepartments[CleanCogName(name)]; ok {
+		return dept
+	}
+	return Unknown
+}
+
+// ParseDepartment parses a department name or abbreviation, such as
+// "sellbot", "sell", or "s".
+func ParseDepartment(s string) (Department, bool) {
+	switch strings.ToLower(s) {
+	case "s", "sell", "sellbot", "sellbots":
+		return Sellbot, true
+	case "c", "m", "cash", "cashbot", "cashbots":
+		return Cashbot, true
+	case "l", "law", "lawbot", "lawbots":
+		return Lawbot, true
+	case "b", "boss", "bossbot", "bossbots":
+		return Bossbot, true
+	}
+	return Unknown, false
+}
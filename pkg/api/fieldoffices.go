@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/kralicky/ttr/pkg/zones"
+)
+
+type FieldOfficesSpec struct {
+	LastUpdated  int64                   `json:"lastUpdated"`
+	FieldOffices map[string]*FieldOffice `json:"fieldOffices"`
+}
+
+type FieldOffice struct {
+	Department string `json:"department"`
+	// Difficulty is 0-based; see Stars.
+	Difficulty int  `json:"difficulty"`
+	Annexes    int  `json:"annexes"`
+	Open       bool `json:"open"`
+	// Unix timestamp of when the field office will close, if it is expiring.
+	Expiring *int64 `json:"expiring"`
+}
+
+// Stars returns the difficulty as shown in game (1-3 stars).
+func (f *FieldOffice) Stars() int {
+	return f.Difficulty + 1
+}
+
+// ExpiresAt returns the time the field office will close, if it is expiring.
+func (f *FieldOffice) ExpiresAt() (time.Time, bool) {
+	if f.Expiring == nil {
+		return time.Time{}, false
+	}
+	return time.Unix(*f.Expiring, 0), true
+}
+
+// ZonedFieldOffice is a field office along with the zone it is located in.
+type ZonedFieldOffice struct {
+	ZoneId int64  `json:"zoneId"`
+	Street string `json:"street"`
+	Hood   string `json:"hood"`
+	*FieldOffice
+}
+
+// List returns the field offices sorted by zone ID, with zone names filled in.
+func (s *FieldOfficesSpec) List() []ZonedFieldOffice {
+	list := make([]ZonedFieldOffice, 0, len(s.FieldOffices))
+	for id, fo := range s.FieldOffices {
+		zoneId, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			continue
+		}
+		list = append(list, ZonedFieldOffice{
+			ZoneId:      zoneId,
+			Street:      zones.StreetName(zoneId),
+			Hood:        zones.HoodName(zoneId),
+			FieldOffice: fo,
+		})
+	}
+	slices.SortFunc(list, func(a, b ZonedFieldOffice) int {
+		return int(a.ZoneId - b.ZoneId)
+	})
+	return list
+}
+
+func (c *client) FieldOffices(ctx context.Context) (*FieldOfficesSpec, error) {
+	var spec FieldOfficesSpec
+	if err := c.getJSON(ctx, "/fieldoffices", &spec); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// HTMLToText converts the HTML used in news posts and release notes into
+// plain text. Block elements are separated by blank lines, list items are
+// prefixed with a bullet, and links are followed by their URL.
+func HTMLToText(s string) string {
+	var out strings.Builder
+	// number of newlines to write before the next text, so that nested or
+	// consecutive block elements don't produce runs of blank lines
+	pendingNewlines := 0
+	needSpace := false
+	emit := func(text string) {
+		if out.Len() > 0 {
+			if pendingNewlines > 0 {
+				out.WriteString(strings.Repeat("\n", pendingNewlines))
+			} else if needSpace {
+				out.WriteByte(' ')
+			}
+		}
+		pendingNewlines = 0
+		needSpace = false
+		out.WriteString(text)
+	}
+	breakLine := func(n int) {
+		pendingNewlines = max(pendingNewlines, n)
+	}
+
+	type link struct {
+		href  string
+		start int
+	}
+	var links []link
+	listDepth := 0
+	skipDepth := 0
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return out.String()
+		case html.TextToken:
+			if skipDepth > 0 {
+				continue
+			}
+			raw := string(z.Text())
+			words := strings.Fields(raw)
+			if len(words) == 0 {
+				if raw != "" {
+					needSpace = true
+				}
+				continue
+			}
+			if strings.TrimLeft(raw, " \t\r\n") != raw {
+				needSpace = true
+			}
+			emit(strings.Join(words, " "))
+			if strings.TrimRight(raw, " \t\r\n") != raw {
+				needSpace = true
+			}
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			tn, hasAttr := z.TagName()
+			start := tt != html.EndTagToken
+			switch tag := string(tn); tag {
+			case "script", "style":
+				if tt == html.StartTagToken {
+					skipDepth++
+				} else if tt == html.EndTagToken {
+					skipDepth = max(skipDepth-1, 0)
+				}
+			case "br":
+				breakLine(1)
+			case "p", "div", "h1", "h2", "h3", "h4", "h5", "h6", "blockquote", "table", "hr":
+				breakLine(2)
+			case "ul", "ol":
+				if start {
+					listDepth++
+				} else {
+					listDepth = max(listDepth-1, 0)
+				}
+				breakLine(2)
+			case "tr":
+				breakLine(1)
+			case "li":
+				if start {
+					breakLine(1)
+					emit(strings.Repeat("  ", max(listDepth-1, 0)) + "•")
+					needSpace = true
+				}
+			case "a":
+				if start {
+					var href string
+					for hasAttr {
+						var key, val []byte
+						key, val, hasAttr = z.TagAttr()
+						if string(key) == "href" {
+							href = string(val)
+						}
+					}
+					links = append(links, link{href: href, start: out.Len()})
+				} else if len(links) > 0 {
+					l := links[len(links)-1]
+					links = links[:len(links)-1]
+					text := strings.TrimSpace(out.String()[l.start:])
+					if l.href != "" && !strings.HasPrefix(l.href, "#") && text != l.href {
+						needSpace = true
+						emit("(" + l.href + ")")
+					}
+				}
+			}
+		}
+	}
+}
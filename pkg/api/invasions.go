@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type InvasionsSpec struct {
+	Error       *string              `json:"error"`
+	Invasions   map[string]*Invasion `json:"invasions"`
+	LastUpdated int64                `json:"lastUpdated"`
+}
+
+type Invasion struct {
+	AsOf     int64    `json:"asOf"`
+	Type     string   `json:"type"`
+	Progress Progress `json:"progress"`
+}
+
+func (i *Invasion) CogName() string {
+	return CleanCogName(i.Type)
+}
+
+func (i *Invasion) Department() Department {
+	return CogDepartment(i.Type)
+}
+
+func (i *Invasion) AsOfTime() time.Time {
+	return time.Unix(i.AsOf, 0)
+}
+
+// Progress is the number of cogs defeated out of the total, given by the API
+// as a string like "123/5000".
+type Progress struct {
+	Defeated int `json:"defeated"`
+	Total    int `json:"total"`
+}
+
+func (p Progress) Remaining() int {
+	return max(p.Total-p.Defeated, 0)
+}
+
+func (p Progress) String() string {
+	return strconv.Itoa(p.Defeated) + "/" + strconv.Itoa(p.Total)
+}
+
+func (p *Progress) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		// accept the structured form as well, as written by MarshalJSON
+		type progress Progress
+		return json.Unmarshal(data, (*progress)(p))
+	}
+	defeated, total, ok := strings.Cut(s, "/")
+	if !ok {
+		return nil
+	}
+	p.Defeated, _ = strconv.Atoi(strings.TrimSpace(defeated))
+	p.Total, _ = strconv.Atoi(strings.TrimSpace(total))
+	return nil
+}
+
+func (c *client) Invasions(ctx context.Context) (*InvasionsSpec, error) {
+	var spec InvasionsSpec
+	if err := c.getJSON(ctx, "/invasions", &spec); err != nil {
+		return nil, err
+	}
+	if spec.Error != nil && *spec.Error != "" {
+		return nil, fmt.Errorf("API error: %s", *spec.Error)
+	}
+	return &spec, nil
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"fmt"
+)
+
+type NewsPost struct {
+	PostId int    `json:"postId"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	// HTML body of the post.
+	Body  string `json:"body"`
+	Date  string `json:"date"`
+	Image string `json:"image,omitempty"`
+}
+
+// Text returns the body of the post as plain text.
+func (p *NewsPost) Text() string {
+	return HTMLToText(p.Body)
+}
+
+type ReleaseNote struct {
+	NoteId int    `json:"noteId"`
+	Slug   string `json:"slug"`
+	Date   string `json:"date"`
+	// HTML body of the release notes. Not set when listing release notes.
+	Body string `json:"body,omitempty"`
+}
+
+// Text returns the body of the release notes as plain text.
+func (n *ReleaseNote) Text() string {
+	return HTMLToText(n.Body)
+}
+
+// LatestNews returns the most recent news post.
+func (c *client) LatestNews(ctx context.Context) (*NewsPost, error) {
+	var post NewsPost
+	if err := c.getJSON(ctx, "/news", &post); err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// NewsList returns all news posts, most recent first.
+func (c *client) NewsList(ctx context.Context) ([]*NewsPost, error) {
+	var posts []*NewsPost
+	if err := c.getJSON(ctx, "/news/list", &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func (c *client) NewsPost(ctx context.Context, id int) (*NewsPost, error) {
+	var post NewsPost
+	if err := c.getJSON(ctx, fmt.Sprintf("/news/%d", id), &post); err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// ReleaseNotes returns a summary of all release notes, most recent first.
+// The bodies are not included; use ReleaseNote to fetch them.
+func (c *client) ReleaseNotes(ctx context.Context) ([]*ReleaseNote, error) {
+	var notes []*ReleaseNote
+	if err := c.getJSON(ctx, "/releasenotes", &notes); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+func (c *client) ReleaseNote(ctx context.Context, id int) (*ReleaseNote, error) {
+	var note ReleaseNote
+	if err := c.getJSON(ctx, fmt.Sprintf("/releasenotes/%d", id), &note); err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type PopulationSpec struct {
+	LastUpdated          int64             `json:"lastUpdated"`
+	TotalPopulation      int               `json:"totalPopulation"`
+	PopulationByDistrict map[string]int    `json:"populationByDistrict"`
+	StatusByDistrict     map[string]string `json:"statusByDistrict"`
+}
+
+const DistrictOnline = "online"
+
+type District struct {
+	Name       string    `json:"name"`
+	Population int       `json:"population"`
+	Status     string    `json:"status"`
+	Invasion   *Invasion `json:"invasion,omitempty"`
+}
+
+func (d District) Online() bool {
+	return d.Status == "" || d.Status == DistrictOnline
+}
+
+// Districts combines population and invasion data into a list of districts,
+// sorted by name. inv may be nil.
+func Districts(pop *PopulationSpec, inv *InvasionsSpec) []District {
+	names := map[string]struct{}{}
+	for name := range pop.PopulationByDistrict {
+		names[name] = struct{}{}
+	}
+	for name := range pop.StatusByDistrict {
+		names[name] = struct{}{}
+	}
+	districts := make([]District, 0, len(names))
+	for name := range names {
+		d := District{
+			Name:       name,
+			Population: pop.PopulationByDistrict[name],
+			Status:     pop.StatusByDistrict[name],
+		}
+		if inv != nil {
+			d.Invasion = inv.Invasions[name]
+		}
+		districts = append(districts, d)
+	}
+	slices.SortFunc(districts, func(a, b District) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return districts
+}
+
+// DistrictPreference selects which districts to recommend.
+type DistrictPreference struct {
+	// If true, only districts with an invasion are recommended.
+	Invasion bool
+	// If set, only invasions of cogs whose name contains this string, or
+	// whose department matches it, are considered.
+	Cog string
+}
+
+// ParseDistrictPreference parses a preference of the form "least-populated",
+// "invasion", or "invasion:<cog or department>".
+func ParseDistrictPreference(s string) (DistrictPreference, error) {
+	kind, arg, _ := strings.Cut(s, ":")
+	switch kind {
+	case "least-populated":
+		if arg != "" {
+			return DistrictPreference{}, fmt.Errorf("least-populated does not take an argument")
+		}
+		return DistrictPreference{}, nil
+	case "invasion":
+		return DistrictPreference{Invasion: true, Cog: arg}, nil
+	default:
+		return DistrictPreference{}, fmt.Errorf("invalid district preference %q (expected least-populated, invasion, or invasion:<cog>)", s)
+	}
+}
+
+func (p DistrictPreference) matches(d District) bool {
+	if !d.Online() {
+		return false
+	}
+	if !p.Invasion {
+		return true
+	}
+	if d.Invasion == nil {
+		return false
+	}
+	if p.Cog == "" {
+		return true
+	}
+	if dept, ok := ParseDepartment(p.Cog); ok {
+		return d.Invasion.Department() == dept
+	}
+	return strings.Contains(strings.ToLower(d.Invasion.CogName()), strings.ToLower(p.Cog))
+}
+
+// RecommendDistricts returns the online districts matching the preference,
+// least populated first.
+func RecommendDistricts(districts []District, pref DistrictPreference) []District {
+	var matched []District
+	for _, d := range districts {
+		if pref.matches(d) {
+			matched = append(matched, d)
+		}
+	}
+	slices.SortStableFunc(matched, func(a, b District) int {
+		return a.Population - b.Population
+	})
+	return matched
+}
+
+func (c *client) Population(ctx context.Context) (*PopulationSpec, error) {
+	var spec PopulationSpec
+	if err := c.getJSON(ctx, "/population", &spec); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
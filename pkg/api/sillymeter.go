@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"time"
+)
+
+type SillyMeterState string
+
+const (
+	// The meter is filling up, and toons are voting for one of the rewards.
+	SillyMeterActive SillyMeterState = "Active"
+	// The meter is full, and the winning reward is active.
+	SillyMeterReward SillyMeterState = "Reward"
+	// The meter is cooling down after a reward.
+	SillyMeterInactive SillyMeterState = "Inactive"
+)
+
+// SillyMeterMaxHP is the number of points needed to fill the Silly Meter.
+const SillyMeterMaxHP = 5_000_000
+
+type SillyMeterSpec struct {
+	State SillyMeterState `json:"state"`
+	HP    int             `json:"hp"`
+	// Rewards that can be voted for, or that were voted for if the state is
+	// Reward or Inactive.
+	Rewards            []string `json:"rewards"`
+	RewardDescriptions []string `json:"rewardDescriptions"`
+	// Points contributed towards each reward, in the same order as Rewards.
+	RewardPoints []int `json:"rewardPoints"`
+	// The active reward, if the state is Reward.
+	Winner              *string `json:"winner"`
+	NextUpdateTimestamp int64   `json:"nextUpdateTimestamp"`
+	AsOf                int64   `json:"asOf"`
+}
+
+// NextUpdate returns the time of the next state change, e.g. when the active
+// reward expires or when the meter starts filling again. For the Active
+// state, it is when the next update is expected, not when the meter will be
+// full.
+func (s *SillyMeterSpec) NextUpdate() time.Time {
+	return time.Unix(s.NextUpdateTimestamp, 0)
+}
+
+// ActiveReward returns the active reward and its description, if any.
+func (s *SillyMeterSpec) ActiveReward() (name string, description string, ok bool) {
+	if s.State != SillyMeterReward || s.Winner == nil {
+		return "", "", false
+	}
+	for i, r := range s.Rewards {
+		if r == *s.Winner && i < len(s.RewardDescriptions) {
+			return r, s.RewardDescriptions[i], true
+		}
+	}
+	return *s.Winner, "", true
+}
+
+// Percent returns how full the meter is, from 0 to 100.
+func (s *SillyMeterSpec) Percent() float64 {
+	return min(float64(s.HP)/SillyMeterMaxHP*100, 100)
+}
+
+type SillyMeterChoice struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Points      int    `json:"points"`
+}
+
+// RewardChoices returns the rewards with their descriptions and points.
+func (s *SillyMeterSpec) RewardChoices() []SillyMeterChoice {
+	choices := make([]SillyMeterChoice, len(s.Rewards))
+	for i, r := range s.Rewards {
+		choices[i].Name = r
+		if i < len(s.RewardDescriptions) {
+			choices[i].Description = s.RewardDescriptions[i]
+		}
+		if i < len(s.RewardPoints) {
+			choices[i].Points = s.RewardPoints[i]
+		}
+	}
+	return choices
+}
+
+func (c *client) SillyMeter(ctx context.Context) (*SillyMeterSpec, error) {
+	var spec SillyMeterSpec
+	if err := c.getJSON(ctx, "/sillymeter", &spec); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+const bundleVersion = 1
+
+// BundlePassphraseEnvVar can be set to export or import bundles without
+// prompting for a passphrase.
+const BundlePassphraseEnvVar = "TTR_BUNDLE_PASSPHRASE"
+
+// Bundle holds the credentials for a set of accounts, for transferring them
+// between machines. Bundles are always stored encrypted with a passphrase.
+type Bundle struct {
+	Version  int              `json:"version"`
+	Accounts []*BundleAccount `json:"accounts"`
+}
+
+type BundleAccount struct {
+	Name            string         `json:"name"`
+	Password        string         `json:"password,omitempty"`
+	TwoFactorSecret string         `json:"twoFactorSecret,omitempty"`
+	RecoveryCodes   []RecoveryCode `json:"recoveryCodes,omitempty"`
+}
+
+// NewBundle collects the stored secrets for the given accounts. Accounts
+// without a saved password or 2FA secret are included with empty fields.
+func NewBundle(accounts []string) (*Bundle, error) {
+	b := &Bundle{
+		Version: bundleVersion,
+	}
+	for _, name := range accounts {
+		acct := &BundleAccount{Name: name}
+		pw, err := GetAccountPassword(name)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("failed to read password for %s: %w", name, err)
+		}
+		acct.Password = pw
+		secret, err := GetTwoFactorAuthSecret(name)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("failed to read 2FA secret for %s: %w", name, err)
+		}
+		acct.TwoFactorSecret = secret
+		codes, err := ListRecoveryCodes(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recovery codes for %s: %w", name, err)
+		}
+		acct.RecoveryCodes = codes
+		b.Accounts = append(b.Accounts, acct)
+	}
+	return b, nil
+}
+
+// Encrypt writes the bundle to w, encrypted with the given passphrase. If
+// armored is true, the output is PEM-encoded.
+func (b *Bundle) Encrypt(w io.Writer, passphrase string, armored bool) error {
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return err
+	}
+	var aw io.WriteCloser
+	if armored {
+		aw = armor.NewWriter(w)
+		w = aw
+	}
+	ew, err := age.Encrypt(w, recipient)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(ew).Encode(b); err != nil {
+		return err
+	}
+	if err := ew.Close(); err != nil {
+		return err
+	}
+	if aw != nil {
+		return aw.Close()
+	}
+	return nil
+}
+
+// DecryptBundle reads a bundle written by Bundle.Encrypt. Both armored and
+// binary bundles are accepted.
+func DecryptBundle(r io.Reader, passphrase string) (*Bundle, error) {
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var src io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		src = armor.NewReader(bytes.NewReader(data))
+	}
+	dr, err := age.Decrypt(src, identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt bundle: %w", err)
+	}
+	var b Bundle
+	if err := json.NewDecoder(dr).Decode(&b); err != nil {
+		return nil, fmt.Errorf("failed to decode bundle: %w", err)
+	}
+	if b.Version != bundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version: %d", b.Version)
+	}
+	return &b, nil
+}
+
+// Restore replaces the stored secrets for the account with the ones in the
+// bundle. Secrets that are not in the bundle are deleted. If a secret can't
+// be stored, the account's previous secrets are put back.
+func (a *BundleAccount) Restore() error {
+	prev, err := NewBundle([]string{a.Name})
+	if err != nil {
+		return err
+	}
+	if err := a.replace(); err != nil {
+		if rerr := prev.Accounts[0].replace(); rerr != nil {
+			return errors.Join(err, fmt.Errorf("failed to roll back secrets for %s: %w", a.Name, rerr))
+		}
+		return err
+	}
+	return nil
+}
+
+func (a *BundleAccount) replace() error {
+	if err := DeleteAllSecrets(a.Name); err != nil {
+		return fmt.Errorf("failed to delete secrets for %s: %w", a.Name, err)
+	}
+	if a.Password != "" {
+		if err := SetAccountPassword(a.Name, a.Password); err != nil {
+			return fmt.Errorf("failed to store password for %s: %w", a.Name, err)
+		}
+	}
+	if a.TwoFactorSecret != "" {
+		if err := ReplaceTwoFactorAuthSecret(a.Name, a.TwoFactorSecret); err != nil {
+			return fmt.Errorf("failed to store 2FA secret for %s: %w", a.Name, err)
+		}
+	}
+	if len(a.RecoveryCodes) > 0 {
+		if err := writeRecoveryCodes(a.Name, a.RecoveryCodes); err != nil {
+			return fmt.Errorf("failed to store recovery codes for %s: %w", a.Name, err)
+		}
+	}
+	return nil
+}
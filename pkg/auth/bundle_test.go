@@ -0,0 +1,57 @@
+package auth_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kralicky/ttr/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	bundle := &auth.Bundle{
+		Version: 1,
+		Accounts: []*auth.BundleAccount{
+			{Name: "toon1", Password: "hunter2", TwoFactorSecret: "JBSWY3DPEHPK3PXP"},
+			{Name: "toon2"},
+		},
+	}
+
+	for _, armored := range []bool{false, true} {
+		var buf bytes.Buffer
+		require.NoError(t, bundle.Encrypt(&buf, "passphrase", armored))
+		assert.NotContains(t, buf.String(), "hunter2")
+
+		_, err := auth.DecryptBundle(bytes.NewReader(buf.Bytes()), "wrong")
+		assert.Error(t, err)
+
+		decrypted, err := auth.DecryptBundle(bytes.NewReader(buf.Bytes()), "passphrase")
+		require.NoError(t, err)
+		assert.Equal(t, bundle, decrypted)
+	}
+}
+
+func TestBundleRestore(t *testing.T) {
+	auth.SetStore(auth.NewMemoryStore())
+	defer auth.SetStore(auth.NewKeyringStore())
+
+	require.NoError(t, auth.SetAccountPassword("toon1", "old"))
+	require.NoError(t, auth.ReplaceTwoFactorAuthSecret("toon1", "JBSWY3DPEHPK3PXP"))
+
+	// secrets missing from the bundle are removed
+	acct := &auth.BundleAccount{Name: "toon1", Password: "new"}
+	require.NoError(t, acct.Restore())
+	pw, err := auth.GetAccountPassword("toon1")
+	require.NoError(t, err)
+	assert.Equal(t, "new", pw)
+	_, err = auth.GetTwoFactorAuthSecret("toon1")
+	assert.ErrorIs(t, err, auth.ErrNotFound)
+
+	// a failed restore puts the previous secrets back
+	acct = &auth.BundleAccount{Name: "toon1", Password: "newer", TwoFactorSecret: "not a secret!"}
+	assert.Error(t, acct.Restore())
+	pw, err = auth.GetAccountPassword("toon1")
+	require.NoError(t, err)
+	assert.Equal(t, "new", pw)
+}
@@ -0,0 +1,16 @@
+package auth
+
+const (
+	serviceNameCompanion = "ttr-cli-companion"
+	companionTokenKey    = "authToken"
+)
+
+// CompanionAuthToken returns the token used to authorize with the companion
+// app API. ErrNotFound is returned if one has not been stored yet.
+func CompanionAuthToken() (string, error) {
+	return secrets().Get(serviceNameCompanion, companionTokenKey)
+}
+
+func SetCompanionAuthToken(token string) error {
+	return secrets().Set(serviceNameCompanion, companionTokenKey, token)
+}
@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/kralicky/ttr/pkg/api"
+)
+
+type LoginStage int
+
+const (
+	StagePassword LoginStage = iota
+	StageTwoFactor
+	StageQueue
+)
+
+func (s LoginStage) String() string {
+	switch s {
+	case StagePassword:
+		return "password"
+	case StageTwoFactor:
+		return "2fa"
+	case StageQueue:
+		return "queue"
+	default:
+		return fmt.Sprintf("LoginStage(%d)", int(s))
+	}
+}
+
+// ErrNoStoredPassword is returned by Login when no password is stored for the
+// account and prompting is disabled.
+var ErrNoStoredPassword = errors.New("no stored password")
+
+// ErrNoStoredTwoFactorSecret is returned by Login when the account requires a
+// two-factor auth code, no secret is stored, and prompting is disabled.
+var ErrNoStoredTwoFactorSecret = errors.New("no stored two-factor auth secret")
+
+type LoginOptions struct {
+	// If true, prompt for the password or two-factor auth code when they are
+	// not stored.
+	Interactive bool
+	// If true, submit a stored recovery code if the two-factor auth code is
+	// rejected. In interactive mode, the user is asked first.
+	RecoveryCodeFallback bool
+	// Called each time a stage of the login flow completes. err is nil if the
+	// stage succeeded.
+	OnStage func(stage LoginStage, err error)
+	// Where progress messages are written. Defaults to os.Stdout.
+	Output io.Writer
+}
+
+// Login runs the full login flow for an account using its stored credentials,
+// and returns the credentials needed to launch the game.
+func Login(ctx context.Context, client api.LoginClient, account string, opts LoginOptions) (*api.LoginSuccessPayload, error) {
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+	report := func(stage LoginStage, err error) error {
+		if opts.OnStage != nil {
+			opts.OnStage(stage, err)
+		}
+		return err
+	}
+
+	var pw string
+	var err error
+	if opts.Interactive {
+		pw, err = GetAccountPasswordOrPrompt(account)
+	} else {
+		pw, err = GetAccountPassword(account)
+		if errors.Is(err, ErrNotFound) {
+			err = ErrNoStoredPassword
+		}
+	}
+	if err != nil {
+		return nil, report(StagePassword, err)
+	}
+
+	resp, err := client.Login(ctx, account, pw)
+	if err != nil {
+		return nil, report(StagePassword, fmt.Errorf("login failed: %w", err))
+	}
+	stage := StagePassword
+	for {
+		switch resp.Success {
+		case api.SuccessTrue:
+			report(stage, nil)
+			return resp.LoginSuccessPayload, nil
+		case api.SuccessFalse:
+			return nil, report(stage, fmt.Errorf("login failed: %s", resp.Message))
+		case api.SuccessPartial:
+			report(stage, nil)
+			stage = StageTwoFactor
+			var code string
+			generated := HasTwoFactorAuthSecret(account)
+			if generated {
+				fmt.Fprintf(opts.Output, "Generating two-factor authentication code for %s...\n", account)
+				code, err = GenerateFreshTwoFactorAuthCode(ctx, account)
+				if err != nil {
+					return nil, report(stage, fmt.Errorf("error generating two-factor authentication code: %w", err))
+				}
+			} else if opts.Interactive {
+				if err := survey.AskOne(&survey.Password{
+					Message: "Enter a two-factor authentication code for " + account + ":",
+				}, &code); err != nil {
+					return nil, report(stage, err)
+				}
+			} else {
+				return nil, report(stage, ErrNoStoredTwoFactorSecret)
+			}
+			token := resp.ResponseToken
+			resp, err = client.CompleteTwoFactorAuth(ctx, token, code)
+			if errors.Is(err, api.ErrTwoFactorRejected) && generated {
+				// the code may have expired in transit, or the clock may be
+				// slightly behind; try once more with the next code
+				fmt.Fprintf(opts.Output, "Code rejected, retrying with the next code for %s...\n", account)
+				if resp != nil && resp.LoginPartialSuccessPayload != nil && resp.ResponseToken != "" {
+					token = resp.ResponseToken
+				}
+				code, err = GenerateTwoFactorAuthCodeAt(account, Now().Add(CodePeriod))
+				if err != nil {
+					return nil, report(stage, fmt.Errorf("error generating two-factor authentication code: %w", err))
+				}
+				resp, err = client.CompleteTwoFactorAuth(ctx, token, code)
+			}
+			if errors.Is(err, api.ErrTwoFactorRejected) && opts.RecoveryCodeFallback && HasRecoveryCodes(account) {
+				if resp != nil && resp.LoginPartialSuccessPayload != nil && resp.ResponseToken != "" {
+					token = resp.ResponseToken
+				}
+				resp, err = completeWithRecoveryCode(ctx, client, account, token, opts)
+			}
+			if err != nil {
+				return nil, report(stage, fmt.Errorf("two-factor authentication failed: %w", err))
+			}
+		case api.SuccessDelayed:
+			if stage != StageQueue {
+				report(stage, nil)
+				stage = StageQueue
+			}
+			if resp.ETA > 0 {
+				select {
+				case <-ctx.Done():
+					return nil, report(stage, ctx.Err())
+				case <-time.After(1 * time.Second):
+				}
+			}
+			resp, err = client.RetryDelayedLogin(ctx, resp.QueueToken)
+			if err != nil {
+				return nil, report(stage, fmt.Errorf("failed to retry delayed login: %w", err))
+			}
+		default:
+			return nil, report(stage, fmt.Errorf("unexpected login response: %q", resp.Success))
+		}
+	}
+}
+
+func completeWithRecoveryCode(ctx context.Context, client api.LoginClient, account, token string, opts LoginOptions) (*api.LoginResponse, error) {
+	if opts.Interactive {
+		var confirm bool
+		if err := survey.AskOne(&survey.Confirm{
+			Message: "Two-factor authentication failed for " + account + ". Use a stored recovery code?",
+		}, &confirm); err != nil {
+			return nil, err
+		}
+		if !confirm {
+			return nil, api.ErrTwoFactorRejected
+		}
+	}
+	code, err := NextRecoveryCode(account)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(opts.Output, "Submitting a recovery code for %s...\n", account)
+	resp, err := client.CompleteTwoFactorAuth(ctx, token, code)
+	if err != nil && resp == nil {
+		// the code never reached the server, and can be used again
+		return nil, err
+	}
+	// the server answered, so the code was consumed or is not valid; either
+	// way it shouldn't be submitted again
+	if markErr := MarkRecoveryCodeUsed(account, code); markErr != nil {
+		return nil, markErr
+	}
+	return resp, err
+}
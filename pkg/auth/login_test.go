@@ -0,0 +1,136 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kralicky/ttr/pkg/api"
+	"github.com/kralicky/ttr/pkg/auth"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLoginClient struct {
+	password  string
+	accept    func(code string) bool
+	queueLeft int
+	codes     []string
+}
+
+func (c *fakeLoginClient) Login(_ context.Context, _, password string) (*api.LoginResponse, error) {
+	if password != c.password {
+		return &api.LoginResponse{Success: api.SuccessFalse, Message: "bad password"}, nil
+	}
+	if c.accept != nil {
+		return &api.LoginResponse{
+			Success:                    api.SuccessPartial,
+			LoginPartialSuccessPayload: &api.LoginPartialSuccessPayload{ResponseToken: "response"},
+		}, nil
+	}
+	return c.next(), nil
+}
+
+func (c *fakeLoginClient) CompleteTwoFactorAuth(_ context.Context, _, code string) (*api.LoginResponse, error) {
+	c.codes = append(c.codes, code)
+	if !c.accept(code) {
+		return &api.LoginResponse{
+			Success:                    api.SuccessPartial,
+			LoginPartialSuccessPayload: &api.LoginPartialSuccessPayload{ResponseToken: "response"},
+		}, api.ErrTwoFactorRejected
+	}
+	return c.next(), nil
+}
+
+func (c *fakeLoginClient) RetryDelayedLogin(context.Context, string) (*api.LoginResponse, error) {
+	return c.next(), nil
+}
+
+func (c *fakeLoginClient) next() *api.LoginResponse {
+	if c.queueLeft > 0 {
+		c.queueLeft--
+		return &api.LoginResponse{
+			Success:                    api.SuccessDelayed,
+			LoginDelayedSuccessPayload: &api.LoginDelayedSuccessPayload{QueueToken: "queue"},
+		}
+	}
+	return &api.LoginResponse{
+		Success:             api.SuccessTrue,
+		LoginSuccessPayload: &api.LoginSuccessPayload{Gameserver: "gs", Cookie: "cookie"},
+	}
+}
+
+func TestLogin(t *testing.T) {
+	auth.SetStore(auth.NewMemoryStore())
+	defer auth.SetStore(auth.NewKeyringStore())
+	require.NoError(t, auth.SetAccountPassword("toon", "pw"))
+
+	run := func(client *fakeLoginClient) (*api.LoginSuccessPayload, map[auth.LoginStage]error, error) {
+		stages := map[auth.LoginStage]error{}
+		creds, err := auth.Login(context.Background(), client, "toon", auth.LoginOptions{
+			OnStage: func(stage auth.LoginStage, err error) {
+				stages[stage] = err
+			},
+		})
+		return creds, stages, err
+	}
+
+	creds, stages, err := run(&fakeLoginClient{password: "pw", queueLeft: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "cookie", creds.Cookie)
+	assert.Equal(t, map[auth.LoginStage]error{
+		auth.StagePassword: nil,
+		auth.StageQueue:    nil,
+	}, stages)
+
+	_, stages, err = run(&fakeLoginClient{password: "other"})
+	assert.Error(t, err)
+	assert.Error(t, stages[auth.StagePassword])
+
+	_, stages, err = run(&fakeLoginClient{password: "pw", accept: func(string) bool { return true }})
+	assert.ErrorIs(t, err, auth.ErrNoStoredTwoFactorSecret)
+	assert.NoError(t, stages[auth.StagePassword])
+	assert.ErrorIs(t, stages[auth.StageTwoFactor], auth.ErrNoStoredTwoFactorSecret)
+
+	require.NoError(t, auth.SetTwoFactorAuthSecret("toon", "JBSWY3DPEHPK3PXP"))
+
+	// the first code is rejected, as if the server clock were one step ahead
+	client := &fakeLoginClient{password: "pw", accept: func(code string) bool {
+		next, _ := totp.GenerateCode("JBSWY3DPEHPK3PXP", auth.Now().Add(auth.CodePeriod))
+		return code == next
+	}}
+	_, stages, err = run(client)
+	require.NoError(t, err)
+	assert.NoError(t, stages[auth.StageTwoFactor])
+	assert.Len(t, client.codes, 2)
+
+	// only one retry is attempted
+	client = &fakeLoginClient{password: "pw", accept: func(string) bool { return false }}
+	_, stages, err = run(client)
+	assert.ErrorIs(t, err, api.ErrTwoFactorRejected)
+	assert.Error(t, stages[auth.StageTwoFactor])
+	assert.Len(t, client.codes, 2)
+
+	// fall back to a recovery code if enabled
+	_, err = auth.AddRecoveryCodes("toon", "recovery-1")
+	require.NoError(t, err)
+	client = &fakeLoginClient{password: "pw", accept: func(code string) bool { return code == "recovery-1" }}
+	_, err = auth.Login(context.Background(), client, "toon", auth.LoginOptions{RecoveryCodeFallback: true})
+	require.NoError(t, err)
+	assert.Len(t, client.codes, 3)
+	assert.False(t, auth.HasRecoveryCodes("toon"))
+
+	// rejected recovery codes are not submitted again
+	_, err = auth.AddRecoveryCodes("toon", "recovery-2", "recovery-3")
+	require.NoError(t, err)
+	client = &fakeLoginClient{password: "pw", accept: func(string) bool { return false }}
+	_, err = auth.Login(context.Background(), client, "toon", auth.LoginOptions{RecoveryCodeFallback: true})
+	assert.ErrorIs(t, err, api.ErrTwoFactorRejected)
+	next, err := auth.NextRecoveryCode("toon")
+	require.NoError(t, err)
+	assert.Equal(t, "recovery-3", next)
+
+	require.NoError(t, auth.DeleteAllSecrets("toon"))
+	_, _, err = run(&fakeLoginClient{password: "pw"})
+	assert.ErrorIs(t, err, auth.ErrNoStoredPassword)
+}
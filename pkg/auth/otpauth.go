@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const otpIssuer = "Toontown Rewritten"
+
+// NormalizeTwoFactorSecret converts a secret as copied from the TTR account
+// page (which may contain spaces and lowercase letters) to canonical base32,
+// and checks that it is valid.
+func NormalizeTwoFactorSecret(secret string) (string, error) {
+	s := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, secret)
+	s = strings.TrimRight(s, "=")
+	if s == "" {
+		return "", errors.New("secret is empty")
+	}
+	if _, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(s); err != nil {
+		return "", fmt.Errorf("secret is not valid base32: %w", err)
+	}
+	return s, nil
+}
+
+// ParseTwoFactorSecret accepts either a base32 secret or an otpauth:// URI,
+// and returns the normalized secret.
+func ParseTwoFactorSecret(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if !strings.HasPrefix(strings.ToLower(input), "otpauth://") {
+		return NormalizeTwoFactorSecret(input)
+	}
+	key, err := otp.NewKeyFromURL(input)
+	if err != nil {
+		return "", fmt.Errorf("invalid otpauth URI: %w", err)
+	}
+	if key.Type() != "totp" {
+		return "", fmt.Errorf("unsupported otpauth type %q (expected totp)", key.Type())
+	}
+	return NormalizeTwoFactorSecret(key.Secret())
+}
+
+// TwoFactorAuthURI returns an otpauth:// URI for the account's stored secret,
+// which can be used to enroll another authenticator app.
+func TwoFactorAuthURI(accountName string) (string, error) {
+	secret, err := GetTwoFactorAuthSecret(accountName)
+	if err != nil {
+		return "", err
+	}
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", otpIssuer)
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + otpIssuer + ":" + accountName,
+		RawQuery: v.Encode(),
+	}
+	return u.String(), nil
+}
+
+// ValidateTwoFactorAuthCode checks a code from an authenticator app against
+// the given secret.
+func ValidateTwoFactorAuthCode(secret, code string) bool {
+	valid, _ := totp.ValidateCustom(strings.TrimSpace(code), secret, Now(), totp.ValidateOpts{
+		Period:    uint(CodePeriod / time.Second),
+		Skew:      1,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+	return valid
+}
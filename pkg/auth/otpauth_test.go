@@ -0,0 +1,82 @@
+package auth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kralicky/ttr/pkg/auth"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTwoFactorSecret(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+		err      bool
+	}{
+		{input: "JBSWY3DPEHPK3PXP", expected: "JBSWY3DPEHPK3PXP"},
+		{input: "jbsw y3dp ehpk 3pxp", expected: "JBSWY3DPEHPK3PXP"},
+		{input: " JBSW-Y3DP-EHPK-3PXP\n", expected: "JBSWY3DPEHPK3PXP"},
+		{input: "JBSWY3DPEHPK3PXP====", expected: "JBSWY3DPEHPK3PXP"},
+		{input: "", err: true},
+		{input: "not base32!", err: true},
+		{input: "JBSWY3DPEHPK3PX1", err: true},
+	}
+	for _, c := range cases {
+		actual, err := auth.NormalizeTwoFactorSecret(c.input)
+		if c.err {
+			assert.Error(t, err, c.input)
+			continue
+		}
+		require.NoError(t, err, c.input)
+		assert.Equal(t, c.expected, actual)
+	}
+}
+
+func TestParseTwoFactorSecret(t *testing.T) {
+	secret, err := auth.ParseTwoFactorSecret("otpauth://totp/Toontown%20Rewritten:toon?secret=jbswy3dpehpk3pxp&issuer=Toontown%20Rewritten")
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", secret)
+
+	_, err = auth.ParseTwoFactorSecret("otpauth://hotp/Toontown%20Rewritten:toon?secret=JBSWY3DPEHPK3PXP&counter=1")
+	assert.Error(t, err)
+
+	secret, err = auth.ParseTwoFactorSecret("jbswy3dpehpk3pxp")
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", secret)
+}
+
+func TestTwoFactorAuthURI(t *testing.T) {
+	auth.SetStore(auth.NewMemoryStore())
+	defer auth.SetStore(auth.NewKeyringStore())
+
+	require.NoError(t, auth.SetTwoFactorAuthSecret("toon", "jbsw y3dp ehpk 3pxp"))
+	assert.Error(t, auth.SetTwoFactorAuthSecret("toon", "JBSWY3DPEHPK3PXP"))
+
+	uri, err := auth.TwoFactorAuthURI("toon")
+	require.NoError(t, err)
+	secret, err := auth.ParseTwoFactorSecret(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", secret)
+
+	code, err := totp.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+	assert.True(t, auth.ValidateTwoFactorAuthCode(secret, code))
+
+	qr, err := auth.RenderQRCode(uri)
+	require.NoError(t, err)
+	assert.NotEmpty(t, qr)
+}
+
+func TestCodeExpiresIn(t *testing.T) {
+	start := time.Unix(1_699_999_990, 0) // 10s into a window
+	assert.Equal(t, 20*time.Second, auth.CodeExpiresIn(start))
+	assert.Equal(t, 30*time.Second, auth.CodeExpiresIn(start.Add(20*time.Second)))
+	assert.Equal(t, 500*time.Millisecond, auth.CodeExpiresIn(start.Add(19500*time.Millisecond)))
+
+	auth.SetClockSkew(-time.Hour)
+	defer auth.SetClockSkew(0)
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), auth.Now(), time.Second)
+}
@@ -4,7 +4,6 @@ import (
 	"errors"
 
 	"github.com/AlecAivazis/survey/v2"
-	"github.com/zalando/go-keyring"
 )
 
 const (
@@ -12,15 +11,15 @@ const (
 )
 
 func GetAccountPassword(user string) (string, error) {
-	return keyring.Get(serviceName, user)
+	return secrets().Get(serviceName, user)
 }
 
 func GetAccountPasswordOrPrompt(user string) (string, error) {
-	pw, err := keyring.Get(serviceName, user)
+	pw, err := secrets().Get(serviceName, user)
 	if err == nil {
 		return pw, nil
 	}
-	if errors.Is(err, keyring.ErrNotFound) {
+	if errors.Is(err, ErrNotFound) {
 		var password string
 		if err := survey.AskOne(&survey.Password{
 			Message: "Password:",
@@ -33,13 +32,16 @@ func GetAccountPasswordOrPrompt(user string) (string, error) {
 }
 
 func SetAccountPassword(user, password string) error {
-	return keyring.Set(serviceName, user, password)
+	if err := secrets().Set(serviceName, user, password); err != nil {
+		return err
+	}
+	return trackSecret(user)
 }
 
 func DeleteAccountPassword(user string) error {
-	err := keyring.Delete(serviceName, user)
-	if errors.Is(err, keyring.ErrNotFound) {
-		return nil
+	err := secrets().Delete(serviceName, user)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
 	}
-	return err
+	return untrackIfUnused(user)
 }
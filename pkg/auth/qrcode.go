@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/boombuler/barcode/qr"
+)
+
+// RenderQRCode renders content as a QR code using unicode block characters,
+// two modules per character vertically. Light modules are drawn as blocks so
+// that the code scans correctly on terminals with a dark background.
+func RenderQRCode(content string) (string, error) {
+	code, err := qr.Encode(content, qr.M, qr.Auto)
+	if err != nil {
+		return "", err
+	}
+	const quiet = 2
+	size := code.Bounds().Dx()
+	light := func(x, y int) bool {
+		x, y = x-quiet, y-quiet
+		if x < 0 || y < 0 || x >= size || y >= size {
+			return true
+		}
+		r, _, _, _ := code.At(x, y).RGBA()
+		return r != 0
+	}
+	var sb strings.Builder
+	for y := 0; y < size+2*quiet; y += 2 {
+		for x := 0; x < size+2*quiet; x++ {
+			top, bottom := light(x, y), y+1 < size+2*quiet && light(x, y+1)
+			switch {
+			case top && bottom:
+				sb.WriteRune('█')
+			case top:
+				sb.WriteRune('▀')
+			case bottom:
+				sb.WriteRune('▄')
+			default:
+				sb.WriteRune(' ')
+			}
+		}
+		sb.WriteRune('\n')
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	serviceNameRecovery = "ttr-cli-recovery"
+)
+
+// ErrNoRecoveryCodes is returned when an account has no unused recovery codes.
+var ErrNoRecoveryCodes = errors.New("no unused recovery codes")
+
+type RecoveryCode struct {
+	Code   string     `json:"code"`
+	UsedAt *time.Time `json:"usedAt,omitempty"`
+}
+
+func (c RecoveryCode) Used() bool {
+	return c.UsedAt != nil
+}
+
+// ListRecoveryCodes returns all recovery codes stored for the account, in the
+// order they were added, including ones that have been used.
+func ListRecoveryCodes(accountName string) ([]RecoveryCode, error) {
+	data, err := secrets().Get(serviceNameRecovery, accountName)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var codes []RecoveryCode
+	if err := json.Unmarshal([]byte(data), &codes); err != nil {
+		return nil, fmt.Errorf("failed to read recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+func writeRecoveryCodes(accountName string, codes []RecoveryCode) error {
+	if len(codes) == 0 {
+		err := secrets().Delete(serviceNameRecovery, accountName)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		return untrackIfUnused(accountName)
+	}
+	data, err := json.Marshal(codes)
+	if err != nil {
+		return err
+	}
+	if err := secrets().Set(serviceNameRecovery, accountName, string(data)); err != nil {
+		return err
+	}
+	return trackSecret(accountName)
+}
+
+// AddRecoveryCodes stores new recovery codes for the account. Codes that are
+// already stored are ignored. Returns the number of codes added.
+func AddRecoveryCodes(accountName string, newCodes ...string) (int, error) {
+	codes, err := ListRecoveryCodes(accountName)
+	if err != nil {
+		return 0, err
+	}
+	var added int
+NEW:
+	for _, code := range newCodes {
+		code = strings.TrimSpace(code)
+		if code == "" {
+			continue
+		}
+		for _, existing := range codes {
+			if existing.Code == code {
+				continue NEW
+			}
+		}
+		codes = append(codes, RecoveryCode{Code: code})
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	return added, writeRecoveryCodes(accountName, codes)
+}
+
+// NextRecoveryCode returns the first unused recovery code for the account,
+// without marking it as used.
+func NextRecoveryCode(accountName string) (string, error) {
+	codes, err := ListRecoveryCodes(accountName)
+	if err != nil {
+		return "", err
+	}
+	for _, code := range codes {
+		if !code.Used() {
+			return code.Code, nil
+		}
+	}
+	return "", ErrNoRecoveryCodes
+}
+
+// HasRecoveryCodes reports whether the account has any unused recovery codes.
+func HasRecoveryCodes(accountName string) bool {
+	_, err := NextRecoveryCode(accountName)
+	return err == nil
+}
+
+// MarkRecoveryCodeUsed marks a recovery code as consumed, so that it will not
+// be offered again.
+func MarkRecoveryCodeUsed(accountName string, code string) error {
+	codes, err := ListRecoveryCodes(accountName)
+	if err != nil {
+		return err
+	}
+	for i := range codes {
+		if codes[i].Code == code {
+			if codes[i].Used() {
+				return fmt.Errorf("recovery code has already been used")
+			}
+			now := time.Now()
+			codes[i].UsedAt = &now
+			return writeRecoveryCodes(accountName, codes)
+		}
+	}
+	return fmt.Errorf("recovery code not found")
+}
+
+// UseRecoveryCode returns the first unused recovery code for the account and
+// marks it as used.
+func UseRecoveryCode(accountName string) (string, error) {
+	code, err := NextRecoveryCode(accountName)
+	if err != nil {
+		return "", err
+	}
+	return code, MarkRecoveryCodeUsed(accountName, code)
+}
+
+// DeleteRecoveryCodes removes all recovery codes stored for the account.
+func DeleteRecoveryCodes(accountName string) error {
+	return writeRecoveryCodes(accountName, nil)
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+const (
+	serviceNameIndex = "ttr-cli-index"
+	indexKey         = "accounts"
+)
+
+// secretServices lists every service that holds per-account secrets.
+var secretServices = []string{
+	serviceName,
+	serviceName2fa,
+	serviceNameRecovery,
+}
+
+// Secret stores cannot be enumerated in general (the OS keyring in particular),
+// so the names of all accounts that have secrets stored are tracked in a
+// separate index entry.
+
+func listIndex() ([]string, error) {
+	data, err := secrets().Get(serviceNameIndex, indexKey)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if data == "" {
+		return nil, nil
+	}
+	return strings.Split(data, "\n"), nil
+}
+
+func writeIndex(names []string) error {
+	if len(names) == 0 {
+		err := secrets().Delete(serviceNameIndex, indexKey)
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	return secrets().Set(serviceNameIndex, indexKey, strings.Join(names, "\n"))
+}
+
+func trackSecret(user string) error {
+	names, err := listIndex()
+	if err != nil {
+		return err
+	}
+	if slices.Contains(names, user) {
+		return nil
+	}
+	return writeIndex(append(names, user))
+}
+
+func untrackSecret(user string) error {
+	names, err := listIndex()
+	if err != nil {
+		return err
+	}
+	idx := slices.Index(names, user)
+	if idx == -1 {
+		return nil
+	}
+	return writeIndex(slices.Delete(names, idx, idx+1))
+}
+
+// untrackIfUnused removes the user from the index once no secrets remain.
+func untrackIfUnused(user string) error {
+	if HasAnySecret(user) {
+		return nil
+	}
+	return untrackSecret(user)
+}
+
+// ListSecretAccounts returns the names of all accounts that have at least one
+// secret stored.
+func ListSecretAccounts() ([]string, error) {
+	return listIndex()
+}
+
+// HasAnySecret reports whether any secret is stored for the given account.
+func HasAnySecret(user string) bool {
+	for _, svc := range secretServices {
+		if _, err := secrets().Get(svc, user); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteAllSecrets removes every secret stored for the given account. Secrets
+// that do not exist are ignored.
+func DeleteAllSecrets(user string) error {
+	var errs []error
+	for _, svc := range secretServices {
+		if err := secrets().Delete(svc, user); err != nil && !errors.Is(err, ErrNotFound) {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return untrackSecret(user)
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNotFound is returned by a SecretStore when the requested secret does not
+// exist.
+var ErrNotFound = errors.New("secret not found")
+
+// SecretStore is a key-value store for secrets, keyed by service and user.
+type SecretStore interface {
+	Get(service, user string) (string, error)
+	Set(service, user, secret string) error
+	Delete(service, user string) error
+}
+
+const (
+	BackendKeyring = "keyring"
+	BackendFile    = "file"
+	BackendPass    = "pass"
+	BackendCommand = "command"
+)
+
+type StoreOptions struct {
+	// Path to the vault file, used by the file backend.
+	FilePath string
+	// Prefix for entries in the password store, used by the pass backend.
+	PassPrefix string
+	// Helper command, used by the command backend.
+	Command string
+}
+
+// NewStore creates a SecretStore for the named backend.
+func NewStore(backend string, opts StoreOptions) (SecretStore, error) {
+	switch backend {
+	case BackendKeyring, "":
+		return NewKeyringStore(), nil
+	case BackendFile:
+		if opts.FilePath == "" {
+			return nil, errors.New("file backend requires a vault path")
+		}
+		return NewFileStore(opts.FilePath, PromptPassphrase), nil
+	case BackendPass:
+		return NewPassStore(opts.PassPrefix), nil
+	case BackendCommand:
+		if opts.Command == "" {
+			return nil, errors.New("command backend requires a helper command")
+		}
+		return NewCommandStore(opts.Command), nil
+	default:
+		return nil, fmt.Errorf("unknown secret backend: %q", backend)
+	}
+}
+
+var (
+	storeMu sync.Mutex
+	store   SecretStore = NewKeyringStore()
+)
+
+// SetStore replaces the SecretStore used by all functions in this package.
+func SetStore(s SecretStore) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+}
+
+// Unlocker is implemented by stores that need to be unlocked, e.g. with a
+// passphrase, before secrets can be read.
+type Unlocker interface {
+	Unlock() error
+}
+
+// UnlockStore unlocks the current store if it needs to be, prompting for a
+// passphrase if necessary. It can be used to get prompts out of the way
+// before the terminal is taken over.
+func UnlockStore() error {
+	if u, ok := secrets().(Unlocker); ok {
+		return u.Unlock()
+	}
+	return nil
+}
+
+func secrets() SecretStore {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	return store
+}
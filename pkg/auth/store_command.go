@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+)
+
+type commandStore struct {
+	command string
+}
+
+// NewCommandStore returns a SecretStore that delegates to an external helper
+// program, similar to git credential helpers.
+//
+// The helper is invoked as "<command> get|store|erase", and is given the
+// attributes of the secret on stdin as key=value lines:
+//
+//	service=ttr-cli
+//	user=toon1
+//	secret=hunter2 (store only)
+//
+// For get, the helper should print a secret=<value> line to stdout, or exit
+// without printing one if the secret does not exist. A non-zero exit status is
+// treated as an error.
+func NewCommandStore(command string) SecretStore {
+	return &commandStore{
+		command: command,
+	}
+}
+
+func (s *commandStore) run(action string, attrs map[string]string) (map[string]string, error) {
+	args, err := shellquote.Split(s.command)
+	if err != nil {
+		return nil, fmt.Errorf("invalid helper command: %w", err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("invalid helper command: %q", s.command)
+	}
+	var stdin strings.Builder
+	for _, key := range []string{"service", "user", "secret"} {
+		if v, ok := attrs[key]; ok {
+			fmt.Fprintf(&stdin, "%s=%s\n", key, v)
+		}
+	}
+	cmd := exec.Command(args[0], append(args[1:], action)...)
+	cmd.Stdin = strings.NewReader(stdin.String())
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("secret helper %s: %w: %s", action, err, strings.TrimSpace(stderr.String()))
+	}
+	out := map[string]string{}
+	scan := bufio.NewScanner(&stdout)
+	for scan.Scan() {
+		if k, v, ok := strings.Cut(scan.Text(), "="); ok {
+			out[k] = v
+		}
+	}
+	return out, nil
+}
+
+func (s *commandStore) Get(service, user string) (string, error) {
+	out, err := s.run("get", map[string]string{"service": service, "user": user})
+	if err != nil {
+		return "", err
+	}
+	secret, ok := out["secret"]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (s *commandStore) Set(service, user, secret string) error {
+	_, err := s.run("store", map[string]string{"service": service, "user": user, "secret": secret})
+	return err
+}
+
+func (s *commandStore) Delete(service, user string) error {
+	_, err := s.run("erase", map[string]string{"service": service, "user": user})
+	return err
+}
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"filippo.io/age"
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// PassphraseEnvVar can be set to unlock the file vault without prompting.
+const PassphraseEnvVar = "TTR_VAULT_PASSPHRASE"
+
+// PromptPassphrase reads the vault passphrase from the environment, or prompts
+// for it if it is not set. If create is true, the vault is new and the
+// passphrase is asked for twice.
+func PromptPassphrase(create bool) (string, error) {
+	if pass, ok := os.LookupEnv(PassphraseEnvVar); ok {
+		return pass, nil
+	}
+	var pass string
+	if err := survey.AskOne(&survey.Password{
+		Message: "Vault passphrase:",
+	}, &pass, survey.WithValidator(survey.Required)); err != nil {
+		return "", err
+	}
+	if create {
+		var confirm string
+		if err := survey.AskOne(&survey.Password{
+			Message: "Confirm vault passphrase:",
+		}, &confirm); err != nil {
+			return "", err
+		}
+		if pass != confirm {
+			return "", errors.New("passphrases do not match")
+		}
+	}
+	return pass, nil
+}
+
+type fileStore struct {
+	path       string
+	passphrase func(create bool) (string, error)
+
+	mu       sync.Mutex
+	unlocked bool
+	pass     string
+	secrets  map[string]map[string]string
+}
+
+// NewFileStore returns a SecretStore that keeps secrets in a local file,
+// encrypted with a passphrase. The passphrase func is called once, the first
+// time the vault is accessed, with create set if the vault file doesn't exist
+// yet.
+func NewFileStore(path string, passphrase func(create bool) (string, error)) SecretStore {
+	return &fileStore{
+		path:       path,
+		passphrase: passphrase,
+	}
+}
+
+func (s *fileStore) Unlock() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.unlock()
+}
+
+func (s *fileStore) unlock() error {
+	if s.unlocked {
+		return nil
+	}
+	f, err := os.Open(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	pass, err := s.passphrase(f == nil)
+	if err != nil {
+		if f != nil {
+			f.Close()
+		}
+		return err
+	}
+	s.secrets = map[string]map[string]string{}
+	if f != nil {
+		defer f.Close()
+		identity, err := age.NewScryptIdentity(pass)
+		if err != nil {
+			return err
+		}
+		r, err := age.Decrypt(f, identity)
+		if err != nil {
+			return fmt.Errorf("failed to unlock vault %s: %w", s.path, err)
+		}
+		if err := json.NewDecoder(r).Decode(&s.secrets); err != nil {
+			return fmt.Errorf("failed to read vault %s: %w", s.path, err)
+		}
+	}
+	s.pass = pass
+	s.unlocked = true
+	return nil
+}
+
+func (s *fileStore) save() error {
+	recipient, err := age.NewScryptRecipient(s.pass)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipient)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(w).Encode(s.secrets); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".vault-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, &buf); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *fileStore) Get(service, user string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.unlock(); err != nil {
+		return "", err
+	}
+	secret, ok := s.secrets[service][user]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (s *fileStore) Set(service, user, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.unlock(); err != nil {
+		return err
+	}
+	if s.secrets[service] == nil {
+		s.secrets[service] = map[string]string{}
+	}
+	s.secrets[service][user] = secret
+	return s.save()
+}
+
+func (s *fileStore) Delete(service, user string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.unlock(); err != nil {
+		return err
+	}
+	if _, ok := s.secrets[service][user]; !ok {
+		return ErrNotFound
+	}
+	delete(s.secrets[service], user)
+	if len(s.secrets[service]) == 0 {
+		delete(s.secrets, service)
+	}
+	return s.save()
+}
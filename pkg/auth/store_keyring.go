@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/zalando/go-keyring"
+)
+
+type keyringStore struct{}
+
+// NewKeyringStore returns a SecretStore backed by the OS keyring.
+func NewKeyringStore() SecretStore {
+	return keyringStore{}
+}
+
+func (keyringStore) Get(service, user string) (string, error) {
+	secret, err := keyring.Get(service, user)
+	return secret, translateKeyringErr(err)
+}
+
+func (keyringStore) Set(service, user, secret string) error {
+	return keyring.Set(service, user, secret)
+}
+
+func (keyringStore) Delete(service, user string) error {
+	return translateKeyringErr(keyring.Delete(service, user))
+}
+
+func translateKeyringErr(err error) error {
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,45 @@
+package auth
+
+import "sync"
+
+type memoryStore struct {
+	mu      sync.Mutex
+	secrets map[string]map[string]string
+}
+
+// NewMemoryStore returns a SecretStore that keeps secrets in memory only.
+func NewMemoryStore() SecretStore {
+	return &memoryStore{
+		secrets: map[string]map[string]string{},
+	}
+}
+
+func (s *memoryStore) Get(service, user string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secret, ok := s.secrets[service][user]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (s *memoryStore) Set(service, user, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.secrets[service] == nil {
+		s.secrets[service] = map[string]string{}
+	}
+	s.secrets[service][user] = secret
+	return nil
+}
+
+func (s *memoryStore) Delete(service, user string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.secrets[service][user]; !ok {
+		return ErrNotFound
+	}
+	delete(s.secrets[service], user)
+	return nil
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+const defaultPassPrefix = "ttr-cli"
+
+type passStore struct {
+	prefix string
+}
+
+// NewPassStore returns a SecretStore backed by pass, the standard unix
+// password manager. Entries are stored as <prefix>/<service>/<user>.
+func NewPassStore(prefix string) SecretStore {
+	if prefix == "" {
+		prefix = defaultPassPrefix
+	}
+	return &passStore{
+		prefix: prefix,
+	}
+}
+
+func (s *passStore) entry(service, user string) string {
+	return path.Join(s.prefix, service, user)
+}
+
+func (s *passStore) run(stdin string, args ...string) (string, error) {
+	cmd := exec.Command("pass", args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if strings.Contains(msg, "is not in the password store") {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("pass %s: %w: %s", args[0], err, msg)
+	}
+	return stdout.String(), nil
+}
+
+func (s *passStore) Get(service, user string) (string, error) {
+	out, err := s.run("", "show", s.entry(service, user))
+	if err != nil {
+		return "", err
+	}
+	// pass only guarantees the secret is on the first line
+	secret, _, _ := strings.Cut(out, "\n")
+	return secret, nil
+}
+
+func (s *passStore) Set(service, user, secret string) error {
+	_, err := s.run(secret+"\n", "insert", "--multiline", "--force", s.entry(service, user))
+	return err
+}
+
+func (s *passStore) Delete(service, user string) error {
+	_, err := s.run("", "rm", "--force", s.entry(service, user))
+	return err
+}
@@ -0,0 +1,200 @@
+package auth_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kralicky/ttr/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStore(t *testing.T, store auth.SecretStore) {
+	t.Helper()
+	_, err := store.Get("svc", "user")
+	assert.ErrorIs(t, err, auth.ErrNotFound)
+
+	require.NoError(t, store.Set("svc", "user", "secret"))
+	require.NoError(t, store.Set("svc", "user2", "secret2"))
+	require.NoError(t, store.Set("svc2", "user", "secret3"))
+
+	secret, err := store.Get("svc", "user")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", secret)
+	secret, err = store.Get("svc2", "user")
+	require.NoError(t, err)
+	assert.Equal(t, "secret3", secret)
+
+	require.NoError(t, store.Set("svc", "user", "updated"))
+	secret, err = store.Get("svc", "user")
+	require.NoError(t, err)
+	assert.Equal(t, "updated", secret)
+
+	require.NoError(t, store.Delete("svc", "user"))
+	_, err = store.Get("svc", "user")
+	assert.ErrorIs(t, err, auth.ErrNotFound)
+	secret, err = store.Get("svc", "user2")
+	require.NoError(t, err)
+	assert.Equal(t, "secret2", secret)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, auth.NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.age")
+	passphrase := func(bool) (string, error) { return "passphrase", nil }
+	testStore(t, auth.NewFileStore(path, passphrase))
+
+	// reopen the vault to make sure everything was persisted
+	store := auth.NewFileStore(path, passphrase)
+	secret, err := store.Get("svc", "user2")
+	require.NoError(t, err)
+	assert.Equal(t, "secret2", secret)
+
+	store = auth.NewFileStore(path, func(bool) (string, error) { return "wrong", nil })
+	_, err = store.Get("svc", "user2")
+	assert.Error(t, err)
+
+	// the passphrase is only asked for once, when unlocking
+	prompts := 0
+	store = auth.NewFileStore(path, func(bool) (string, error) {
+		prompts++
+		return "passphrase", nil
+	})
+	auth.SetStore(store)
+	defer auth.SetStore(auth.NewKeyringStore())
+	require.NoError(t, auth.UnlockStore())
+	_, err = store.Get("svc", "user2")
+	require.NoError(t, err)
+	assert.Equal(t, 1, prompts)
+}
+
+func TestFileStorePassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.age")
+	var created []bool
+	passphrase := func(create bool) (string, error) {
+		created = append(created, create)
+		return "passphrase", nil
+	}
+	require.NoError(t, auth.NewFileStore(path, passphrase).Set("svc", "user", "secret"))
+	_, err := auth.NewFileStore(path, passphrase).Get("svc", "user")
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, created)
+
+	// the passphrase can be set in the environment instead of prompting
+	t.Setenv(auth.PassphraseEnvVar, "passphrase")
+	store, err := auth.NewStore(auth.BackendFile, auth.StoreOptions{FilePath: path})
+	require.NoError(t, err)
+	auth.SetStore(store)
+	defer auth.SetStore(auth.NewKeyringStore())
+	require.NoError(t, auth.UnlockStore())
+	secret, err := store.Get("svc", "user")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", secret)
+
+	t.Setenv(auth.PassphraseEnvVar, "wrong")
+	store, err = auth.NewStore(auth.BackendFile, auth.StoreOptions{FilePath: path})
+	require.NoError(t, err)
+	auth.SetStore(store)
+	assert.Error(t, auth.UnlockStore())
+}
+
+const helperScript = `#!/bin/sh
+dir="$(dirname "$0")/data"
+mkdir -p "$dir"
+while IFS='=' read -r key value; do
+	eval "attr_$key=\$value"
+done
+file="$dir/$attr_service.$attr_user"
+case "$1" in
+get) [ -f "$file" ] && echo "secret=$(cat "$file")" ;;
+store) printf '%s' "$attr_secret" > "$file" ;;
+erase) rm -f "$file" ;;
+esac
+exit 0
+`
+
+func TestCommandStore(t *testing.T) {
+	helper := filepath.Join(t.TempDir(), "helper.sh")
+	require.NoError(t, os.WriteFile(helper, []byte(helperScript), 0o755))
+
+	store := auth.NewCommandStore(helper)
+	testStore(t, store)
+}
+
+func TestSecretIndex(t *testing.T) {
+	auth.SetStore(auth.NewMemoryStore())
+	defer auth.SetStore(auth.NewKeyringStore())
+
+	require.NoError(t, auth.SetAccountPassword("toon1", "pw"))
+	require.NoError(t, auth.SetTwoFactorAuthSecret("toon1", "JBSWY3DPEHPK3PXP"))
+	require.NoError(t, auth.SetAccountPassword("toon2", "pw"))
+
+	names, err := auth.ListSecretAccounts()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"toon1", "toon2"}, names)
+
+	// the account stays in the index until all of its secrets are gone
+	require.NoError(t, auth.DeleteAccountPassword("toon1"))
+	names, err = auth.ListSecretAccounts()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"toon1", "toon2"}, names)
+
+	require.NoError(t, auth.DeleteTwoFactorAuthSecret("toon1"))
+	names, err = auth.ListSecretAccounts()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"toon2"}, names)
+
+	require.NoError(t, auth.DeleteAllSecrets("toon2"))
+	assert.False(t, auth.HasAnySecret("toon2"))
+	names, err = auth.ListSecretAccounts()
+	require.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	auth.SetStore(auth.NewMemoryStore())
+	defer auth.SetStore(auth.NewKeyringStore())
+
+	assert.False(t, auth.HasRecoveryCodes("toon"))
+	added, err := auth.AddRecoveryCodes("toon", "aaaa-1111", " bbbb-2222 ", "aaaa-1111", "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, added)
+	added, err = auth.AddRecoveryCodes("toon", "bbbb-2222", "cccc-3333")
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+
+	names, err := auth.ListSecretAccounts()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"toon"}, names)
+
+	code, err := auth.UseRecoveryCode("toon")
+	require.NoError(t, err)
+	assert.Equal(t, "aaaa-1111", code)
+	assert.Error(t, auth.MarkRecoveryCodeUsed("toon", "aaaa-1111"))
+	require.NoError(t, auth.MarkRecoveryCodeUsed("toon", "cccc-3333"))
+
+	code, err = auth.NextRecoveryCode("toon")
+	require.NoError(t, err)
+	assert.Equal(t, "bbbb-2222", code)
+
+	codes, err := auth.ListRecoveryCodes("toon")
+	require.NoError(t, err)
+	require.Len(t, codes, 3)
+	assert.True(t, codes[0].Used())
+	assert.False(t, codes[1].Used())
+	assert.True(t, codes[2].Used())
+
+	_, err = auth.UseRecoveryCode("toon")
+	require.NoError(t, err)
+	_, err = auth.UseRecoveryCode("toon")
+	assert.ErrorIs(t, err, auth.ErrNoRecoveryCodes)
+
+	require.NoError(t, auth.DeleteAllSecrets("toon"))
+	codes, err = auth.ListRecoveryCodes("toon")
+	require.NoError(t, err)
+	assert.Empty(t, codes)
+}
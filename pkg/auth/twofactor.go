@@ -1,35 +1,96 @@
 package auth
 
 import (
+	"context"
 	"errors"
+	"sync/atomic"
 	"time"
 
 	"github.com/pquerna/otp/totp"
-	"github.com/zalando/go-keyring"
 )
 
 const (
 	serviceName2fa = "ttr-cli-2fa"
 )
 
+const (
+	// CodePeriod is how long each TOTP code is valid for.
+	CodePeriod = 30 * time.Second
+	// MinCodeValidity is the minimum time a code must remain valid for before
+	// it is submitted.
+	MinCodeValidity = 3 * time.Second
+)
+
+var clockSkew atomic.Int64
+
+// SetClockSkew sets an offset that is added to the system clock when
+// generating codes, to correct for a system clock that is not accurate.
+func SetClockSkew(d time.Duration) {
+	clockSkew.Store(int64(d))
+}
+
+// Now returns the current time, adjusted by the configured clock skew.
+func Now() time.Time {
+	return time.Now().Add(time.Duration(clockSkew.Load()))
+}
+
+// CodeExpiresIn returns how long the code that is valid at time t remains
+// valid for.
+func CodeExpiresIn(t time.Time) time.Duration {
+	return CodePeriod - time.Duration(t.UnixNano()%int64(CodePeriod))
+}
+
 func SetTwoFactorAuthSecret(accountName string, secret string) error {
-	if _, err := keyring.Get(serviceName2fa, accountName); err == nil {
+	if _, err := secrets().Get(serviceName2fa, accountName); err == nil {
 		return errors.New("2FA secret already exists for this account; delete it first")
 	}
-	return keyring.Set(serviceName2fa, accountName, secret)
+	return ReplaceTwoFactorAuthSecret(accountName, secret)
+}
+
+// ReplaceTwoFactorAuthSecret stores a 2FA secret for the account, overwriting
+// any existing secret.
+func ReplaceTwoFactorAuthSecret(accountName string, secret string) error {
+	secret, err := NormalizeTwoFactorSecret(secret)
+	if err != nil {
+		return err
+	}
+	if err := secrets().Set(serviceName2fa, accountName, secret); err != nil {
+		return err
+	}
+	return trackSecret(accountName)
 }
 
 func GetTwoFactorAuthSecret(accountName string) (string, error) {
-	return keyring.Get(serviceName2fa, accountName)
+	return secrets().Get(serviceName2fa, accountName)
 }
 
 func GenerateTwoFactorAuthCode(accountName string) (string, error) {
+	return GenerateTwoFactorAuthCodeAt(accountName, Now())
+}
+
+// GenerateTwoFactorAuthCodeAt generates the code that is valid at time t.
+func GenerateTwoFactorAuthCodeAt(accountName string, t time.Time) (string, error) {
 	secret, err := GetTwoFactorAuthSecret(accountName)
 	if err != nil {
 		return "", err
 	}
 
-	return totp.GenerateCode(secret, time.Now())
+	return totp.GenerateCode(secret, t)
+}
+
+// GenerateFreshTwoFactorAuthCode generates a code that will remain valid for at
+// least MinCodeValidity. If the current code expires sooner than that, it
+// waits for the next code instead, so that a code is never submitted just as
+// it expires.
+func GenerateFreshTwoFactorAuthCode(ctx context.Context, accountName string) (string, error) {
+	if remaining := CodeExpiresIn(Now()); remaining < MinCodeValidity {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(remaining):
+		}
+	}
+	return GenerateTwoFactorAuthCode(accountName)
 }
 
 func HasTwoFactorAuthSecret(accountName string) bool {
@@ -38,5 +99,8 @@ func HasTwoFactorAuthSecret(accountName string) bool {
 }
 
 func DeleteTwoFactorAuthSecret(accountName string) error {
-	return keyring.Delete(serviceName2fa, accountName)
+	if err := secrets().Delete(serviceName2fa, accountName); err != nil {
+		return err
+	}
+	return untrackIfUnused(accountName)
 }
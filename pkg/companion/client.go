@@ -0,0 +1,214 @@
+// Package companion is a client for the Companion App API, a local HTTP API
+// served by each running game engine that exposes the state of the logged in
+// toon.
+//
+// The first request from a client prompts the player in game to allow or deny
+// access. Requests block until the player answers, so callers should use a
+// context with a generous timeout.
+package companion
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The engine listens on the first free port in this range, so up to 6
+// engines can be queried at once.
+const (
+	PortRangeStart = 1547
+	PortRangeEnd   = 1552
+)
+
+const defaultUserAgent = "ttr-cli"
+
+// ErrNotAuthorized is returned when the player denied access in game, or the
+// engine did not accept the authorization token.
+var ErrNotAuthorized = errors.New("companion app access was not authorized in game")
+
+type Client interface {
+	Port() int
+	Info(ctx context.Context) (*Info, error)
+	All(ctx context.Context) (*All, error)
+}
+
+type client struct {
+	httpClient *http.Client
+	host       string
+	port       int
+	authToken  string
+	userAgent  string
+}
+
+type ClientOptions struct {
+	host      string
+	authToken string
+	userAgent string
+}
+
+type ClientOption func(*ClientOptions)
+
+func (o *ClientOptions) apply(opts ...ClientOption) {
+	for _, op := range opts {
+		op(o)
+	}
+}
+
+// WithAuthToken sets the token sent in the Authorization header. The player
+// is only prompted once per token, so the same token should be reused across
+// runs. If not set, a random token is generated.
+func WithAuthToken(token string) ClientOption {
+	return func(o *ClientOptions) {
+		o.authToken = token
+	}
+}
+
+// WithUserAgent sets the User-Agent header, which is shown to the player when
+// prompting for authorization.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(o *ClientOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithHost overrides the address the engine is contacted on, e.g. to use a
+// test server. The Host header is always sent as localhost:<port>, as
+// required by the engine.
+func WithHost(host string) ClientOption {
+	return func(o *ClientOptions) {
+		o.host = host
+	}
+}
+
+// NewAuthToken returns a new random authorization token.
+func NewAuthToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func NewClient(port int, opts ...ClientOption) Client {
+	options := ClientOptions{
+		host:      "localhost",
+		userAgent: defaultUserAgent,
+	}
+	options.apply(opts...)
+	if options.authToken == "" {
+		options.authToken = NewAuthToken()
+	}
+
+	return &client{
+		httpClient: &http.Client{},
+		host:       options.host,
+		port:       port,
+		authToken:  options.authToken,
+		userAgent:  options.userAgent,
+	}
+}
+
+func (c *client) Port() int {
+	return c.port
+}
+
+func (c *client) Info(ctx context.Context) (*Info, error) {
+	var info Info
+	if err := c.getJSON(ctx, "/info.json", &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (c *client) All(ctx context.Context) (*All, error) {
+	var all All
+	if err := c.getJSON(ctx, "/all.json", &all); err != nil {
+		return nil, err
+	}
+	return &all, nil
+}
+
+func (c *client) getJSON(ctx context.Context, path string, out any) error {
+	addr := net.JoinHostPort(c.host, strconv.Itoa(c.port))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Host = "localhost:" + strconv.Itoa(c.port)
+	req.Header.Set("Authorization", c.authToken)
+	req.Header.Set("User-Agent", c.userAgent)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden:
+		return ErrNotAuthorized
+	case resp.StatusCode/100 != 2:
+		respData, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("companion API error: %s: %s", resp.Status, string(respData))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Engine is a running game engine found by Discover.
+type Engine struct {
+	Port int `json:"port"`
+	// The process ID of the engine, or 0 if it could not be determined.
+	Pid int `json:"pid,omitempty"`
+	// The account the engine was launched for, if it was launched by this
+	// tool and could be determined from the process environment.
+	Account string `json:"account,omitempty"`
+}
+
+// Discover returns the engines listening on the given ports, or on the
+// known port range if none are given. Finding an engine does not send any
+// requests to it, so the player is not prompted.
+func Discover(ctx context.Context, ports ...int) []Engine {
+	if len(ports) == 0 {
+		for p := PortRangeStart; p <= PortRangeEnd; p++ {
+			ports = append(ports, p)
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var engines []Engine
+	for _, port := range ports {
+		port := port
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d := net.Dialer{Timeout: 500 * time.Millisecond}
+			conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort("localhost", strconv.Itoa(port)))
+			if err != nil {
+				return
+			}
+			conn.Close()
+			e := Engine{Port: port}
+			e.Pid = listenerPid(port)
+			if e.Pid != 0 {
+				e.Account = processAccount(e.Pid)
+			}
+			mu.Lock()
+			engines = append(engines, e)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	slices.SortFunc(engines, func(a, b Engine) int {
+		return a.Port - b.Port
+	})
+	return engines
+}
@@ -0,0 +1,133 @@
+package companion_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kralicky/ttr/pkg/companion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEngine serves the fixtures in testdata the same way the game engine
+// serves the companion app API, including the authorization prompt.
+type fakeEngine struct {
+	port int
+
+	mu sync.Mutex
+	// if true, the player denies access when prompted
+	deny       bool
+	prompts    int
+	authorized map[string]bool
+}
+
+func newFakeEngine(t *testing.T) *fakeEngine {
+	t.Helper()
+	e := &fakeEngine{authorized: map[string]bool{}}
+	srv := httptest.NewServer(http.HandlerFunc(e.serveHTTP))
+	t.Cleanup(srv.Close)
+	e.port = srv.Listener.Addr().(*net.TCPAddr).Port
+	return e
+}
+
+func (e *fakeEngine) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Host != "localhost:"+strconv.Itoa(e.port) {
+		http.Error(w, "invalid host", http.StatusBadRequest)
+		return
+	}
+	token := r.Header.Get("Authorization")
+	if token == "" {
+		http.Error(w, "missing authorization", http.StatusUnauthorized)
+		return
+	}
+	e.mu.Lock()
+	if !e.authorized[token] {
+		e.prompts++
+		if e.deny {
+			e.mu.Unlock()
+			http.Error(w, "denied", http.StatusForbidden)
+			return
+		}
+		e.authorized[token] = true
+	}
+	e.mu.Unlock()
+
+	data, err := os.ReadFile(filepath.Join("testdata", strings.TrimPrefix(r.URL.Path, "/")))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (e *fakeEngine) client(opts ...companion.ClientOption) companion.Client {
+	return companion.NewClient(e.port, append([]companion.ClientOption{companion.WithHost("127.0.0.1")}, opts...)...)
+}
+
+func TestInfo(t *testing.T) {
+	engine := newFakeEngine(t)
+	client := engine.client(companion.WithAuthToken("token"))
+
+	info, err := client.Info(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Flippy Doodlenutter", info.Toon.Name)
+	assert.Equal(t, companion.Laff{Current: 112, Max: 137}, info.Laff)
+	assert.Equal(t, "Walrus Way", info.Location.Zone)
+	assert.Equal(t, "Kaboom Cliffs", info.Location.District)
+
+	require.Len(t, info.Gags, 3)
+	assert.Nil(t, info.Gags["Trap"])
+	assert.Equal(t, "Juggling Balls", info.Gags["Toon-Up"].Gag.Name)
+	assert.Nil(t, info.Gags["Toon-Up"].Organic)
+	assert.Equal(t, 7, info.Gags["Throw"].Organic.Value)
+
+	require.Len(t, info.Tasks, 1)
+	assert.Equal(t, 3, info.Tasks[0].Objective.Progress.Current)
+	assert.Equal(t, "Toon HQ", info.Tasks[0].To.Building)
+
+	all, err := client.All(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Flippy Doodlenutter", all.Toon.Name)
+	assert.Equal(t, companion.Beans{Jar: 1520, Bank: 11200}, all.Inventory.Beans)
+	assert.Contains(t, all.Inventory.Other, "fish")
+
+	// the player is only prompted once per token
+	assert.Equal(t, 1, engine.prompts)
+	_, err = engine.client(companion.WithAuthToken("other")).Info(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, engine.prompts)
+}
+
+func TestNotAuthorized(t *testing.T) {
+	engine := newFakeEngine(t)
+	engine.deny = true
+	_, err := engine.client().Info(context.Background())
+	assert.ErrorIs(t, err, companion.ErrNotAuthorized)
+}
+
+func TestDiscover(t *testing.T) {
+	engine := newFakeEngine(t)
+
+	// find a port that nothing is listening on
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unused := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	engines := companion.Discover(context.Background(), unused, engine.port)
+	require.Len(t, engines, 1)
+	assert.Equal(t, engine.port, engines[0].Port)
+	if runtime.GOOS == "linux" {
+		assert.Equal(t, os.Getpid(), engines[0].Pid)
+	}
+}
@@ -0,0 +1,75 @@
+//go:build linux
+
+package companion
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kralicky/ttr/pkg/gameenv"
+)
+
+// listenerPid returns the ID of the process listening on the given local TCP
+// port, or 0 if it can't be found (e.g. it belongs to another user).
+func listenerPid(port int) int {
+	inode := listenerInode(port)
+	if inode == "" {
+		return 0
+	}
+	target := "socket:[" + inode + "]"
+	fds, _ := filepath.Glob("/proc/[0-9]*/fd/*")
+	for _, fd := range fds {
+		if link, err := os.Readlink(fd); err == nil && link == target {
+			pid, _ := strconv.Atoi(strings.Split(fd, "/")[2])
+			return pid
+		}
+	}
+	return 0
+}
+
+func listenerInode(port int) string {
+	portHex := fmt.Sprintf(":%04X", port)
+	for _, name := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		f, err := os.Open(name)
+		if err != nil {
+			continue
+		}
+		scan := bufio.NewScanner(f)
+		scan.Scan() // header
+		for scan.Scan() {
+			// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+			fields := strings.Fields(scan.Text())
+			if len(fields) < 10 {
+				continue
+			}
+			// 0A is the LISTEN state
+			if strings.HasSuffix(fields[1], portHex) && fields[3] == "0A" {
+				f.Close()
+				return fields[9]
+			}
+		}
+		f.Close()
+	}
+	return ""
+}
+
+// processAccount returns the account a game process was launched for, read
+// from its environment.
+func processAccount(pid int) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/environ", pid))
+	if err != nil {
+		return ""
+	}
+	prefix := []byte(gameenv.Account + "=")
+	for _, kv := range bytes.Split(data, []byte{0}) {
+		if bytes.HasPrefix(kv, prefix) {
+			return string(kv[len(prefix):])
+		}
+	}
+	return ""
+}
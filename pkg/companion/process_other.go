@@ -0,0 +1,11 @@
+//go:build !linux
+
+package companion
+
+func listenerPid(port int) int {
+	return 0
+}
+
+func processAccount(pid int) string {
+	return ""
+}
@@ -0,0 +1,114 @@
+package companion
+
+import "encoding/json"
+
+type Toon struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Species   string `json:"species"`
+	HeadColor string `json:"headColor"`
+	Style     string `json:"style"`
+}
+
+type Laff struct {
+	Current int `json:"current"`
+	Max     int `json:"max"`
+}
+
+type Location struct {
+	Zone         string `json:"zone"`
+	Neighborhood string `json:"neighborhood"`
+	District     string `json:"district"`
+	// True if the toon is in an instanced area (e.g. an estate or a cog
+	// facility), where other toons in the district can't join.
+	Instanced bool `json:"instanced"`
+}
+
+type Gag struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+type Experience struct {
+	Current int `json:"current"`
+	Next    int `json:"next"`
+}
+
+// GagTrack is the state of one gag track. Gag is nil if the track has not
+// been unlocked.
+type GagTrack struct {
+	Gag        *Gag       `json:"gag"`
+	Organic    *Gag       `json:"organic"`
+	Experience Experience `json:"experience"`
+}
+
+type TaskProgress struct {
+	Text    string `json:"text"`
+	Current int    `json:"current"`
+	Target  int    `json:"target"`
+}
+
+type TaskObjective struct {
+	Text     string       `json:"text"`
+	Where    string       `json:"where"`
+	Progress TaskProgress `json:"progress"`
+}
+
+type TaskLocation struct {
+	Name         string `json:"name"`
+	Building     string `json:"building"`
+	Zone         string `json:"zone"`
+	Neighborhood string `json:"neighborhood"`
+}
+
+type Task struct {
+	Objective TaskObjective `json:"objective"`
+	From      *TaskLocation `json:"from"`
+	To        *TaskLocation `json:"to"`
+	Reward    string        `json:"reward"`
+	Deletable bool          `json:"deletable"`
+}
+
+// Info is the toon state returned by /info.json.
+type Info struct {
+	Toon     Toon                 `json:"toon"`
+	Laff     Laff                 `json:"laff"`
+	Location Location             `json:"location"`
+	Gags     map[string]*GagTrack `json:"gags"`
+	Tasks    []Task               `json:"tasks"`
+}
+
+type Beans struct {
+	Jar  int `json:"jar"`
+	Bank int `json:"bank"`
+}
+
+// Inventory is the toon's inventory, returned by /all.json. Sections that are
+// not modeled here are kept as raw JSON in Other.
+type Inventory struct {
+	Beans Beans                      `json:"beans"`
+	Other map[string]json.RawMessage `json:"-"`
+}
+
+// All is the full toon state returned by /all.json.
+type All struct {
+	Info
+	Inventory Inventory `json:"inventory"`
+}
+
+func (i *Inventory) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if beans, ok := fields["beans"]; ok {
+		if err := json.Unmarshal(beans, &i.Beans); err != nil {
+			return err
+		}
+		delete(fields, "beans")
+	}
+	if len(fields) > 0 {
+		i.Other = fields
+	}
+	return nil
+}
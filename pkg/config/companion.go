@@ -0,0 +1,17 @@
+package config
+
+import "github.com/spf13/viper"
+
+const (
+	companionAuthTokenKey = "companion.authToken"
+)
+
+// CompanionAuthToken returns the companion app token saved by older versions,
+// which kept it in the config file instead of the secret store.
+func CompanionAuthToken() string {
+	return viper.GetString(companionAuthTokenKey)
+}
+
+func SetCompanionAuthToken(token string) {
+	viper.Set(companionAuthTokenKey, token)
+}
@@ -25,4 +25,6 @@ func Save() error {
 
 func setDefaults() {
 	viper.SetDefault(accountsKey, []string{})
+	viper.SetDefault(secretsBackendKey, "keyring")
+	viper.SetDefault(mapRendererKey, "window")
 }
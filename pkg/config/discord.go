@@ -0,0 +1,35 @@
+package config
+
+import "github.com/spf13/viper"
+
+const (
+	discordEnabledKey          = "discord.enabled"
+	discordClientIdKey         = "discord.clientId"
+	discordLargeImageKey       = "discord.largeImage"
+	discordDisabledAccountsKey = "discord.disabledAccounts"
+)
+
+// DiscordEnabled returns whether the toons' zones should be shown as Discord
+// Rich Presence activity.
+func DiscordEnabled() bool {
+	return viper.GetBool(discordEnabledKey)
+}
+
+// DiscordClientId returns the ID of the Discord application the activity is
+// shown for. Rich Presence requires an application registered in the
+// Discord developer portal; its name is shown as the game being played.
+func DiscordClientId() string {
+	return viper.GetString(discordClientIdKey)
+}
+
+// DiscordLargeImage returns the key of the application's art asset to show
+// with the activity, or "" for none.
+func DiscordLargeImage() string {
+	return viper.GetString(discordLargeImageKey)
+}
+
+// DiscordDisabledAccounts returns the accounts that are never shown in the
+// Discord activity.
+func DiscordDisabledAccounts() []string {
+	return viper.GetStringSlice(discordDisabledAccountsKey)
+}
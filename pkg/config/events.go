@@ -0,0 +1,14 @@
+package config
+
+import "github.com/spf13/viper"
+
+const (
+	eventSinksKey = "events.sinks"
+)
+
+// EventSinks returns the destinations game events are published to, as
+// newline-delimited JSON. Each destination is a file path, a unix socket
+// (unix:<path>) or a webhook URL (http:// or https://).
+func EventSinks() []string {
+	return viper.GetStringSlice(eventSinksKey)
+}
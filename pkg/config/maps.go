@@ -0,0 +1,19 @@
+package config
+
+import "github.com/spf13/viper"
+
+const (
+	mapRendererKey = "maps.renderer"
+	mapPathKey     = "maps.path"
+)
+
+// MapRenderer returns how mint maps are shown (see game.MapRenderers).
+func MapRenderer() string {
+	return viper.GetString(mapRendererKey)
+}
+
+// MapPath returns the path mint maps are written to by the file and open
+// renderers, or "" for the default.
+func MapPath() string {
+	return viper.GetString(mapPathKey)
+}
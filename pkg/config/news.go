@@ -0,0 +1,17 @@
+package config
+
+import "github.com/spf13/viper"
+
+const (
+	lastSeenReleaseNoteKey = "news.lastSeenReleaseNote"
+)
+
+// LastSeenReleaseNote returns the ID of the most recent release notes shown
+// after a game update, or 0 if none have been shown yet.
+func LastSeenReleaseNote() int {
+	return viper.GetInt(lastSeenReleaseNoteKey)
+}
+
+func SetLastSeenReleaseNote(id int) {
+	viper.Set(lastSeenReleaseNoteKey, id)
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	notificationsEnabledKey = "notifications.enabled"
+	notificationsRulesKey   = "notifications.rules"
+)
+
+// NotificationsEnabled returns whether desktop notifications should be sent
+// for game events.
+func NotificationsEnabled() bool {
+	return viper.GetBool(notificationsEnabledKey)
+}
+
+// NotificationRules returns the fields of each configured notification rule,
+// e.g.
+//
+//	notifications:
+//	  enabled: true
+//	  rules:
+//	    - event: zone
+//	      where: mintInterior
+//	    - event: disconnect
+//
+// If no rules are configured, nil is returned.
+func NotificationRules() ([]map[string]string, error) {
+	if !viper.IsSet(notificationsRulesKey) {
+		return nil, nil
+	}
+	list, ok := viper.Get(notificationsRulesKey).([]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s: expected a list", notificationsRulesKey)
+	}
+	rules := make([]map[string]string, len(list))
+	for i, item := range list {
+		fields, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s[%d]: expected a map", notificationsRulesKey, i)
+		}
+		rules[i] = make(map[string]string, len(fields))
+		for k, v := range fields {
+			rules[i][k] = fmt.Sprint(v)
+		}
+	}
+	return rules, nil
+}
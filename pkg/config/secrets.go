@@ -0,0 +1,28 @@
+package config
+
+import "github.com/spf13/viper"
+
+const (
+	secretsBackendKey    = "secrets.backend"
+	secretsFilePathKey   = "secrets.file.path"
+	secretsPassPrefixKey = "secrets.pass.prefix"
+	secretsCommandKey    = "secrets.command"
+)
+
+// SecretBackend returns the name of the backend used to store passwords and
+// other secrets (keyring, file, pass, or command).
+func SecretBackend() string {
+	return viper.GetString(secretsBackendKey)
+}
+
+func SecretFilePath() string {
+	return viper.GetString(secretsFilePathKey)
+}
+
+func SecretPassPrefix() string {
+	return viper.GetString(secretsPassPrefixKey)
+}
+
+func SecretCommand() string {
+	return viper.GetString(secretsCommandKey)
+}
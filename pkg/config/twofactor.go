@@ -0,0 +1,17 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	clockSkewKey = "twoFactor.clockSkew"
+)
+
+// ClockSkew returns the offset added to the system clock when generating
+// two-factor auth codes (e.g. "5s" if the system clock is 5 seconds behind).
+func ClockSkew() time.Duration {
+	return viper.GetDuration(clockSkewKey)
+}
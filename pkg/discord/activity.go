@@ -0,0 +1,19 @@
+package discord
+
+// Activity is a Rich Presence activity.
+type Activity struct {
+	Details    string      `json:"details,omitempty"`
+	State      string      `json:"state,omitempty"`
+	Timestamps *Timestamps `json:"timestamps,omitempty"`
+	Assets     *Assets     `json:"assets,omitempty"`
+}
+
+type Timestamps struct {
+	// Unix time in milliseconds.
+	Start int64 `json:"start,omitempty"`
+}
+
+type Assets struct {
+	LargeImage string `json:"large_image,omitempty"`
+	LargeText  string `json:"large_text,omitempty"`
+}
@@ -0,0 +1,272 @@
+package discord_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kralicky/ttr/pkg/discord"
+	"github.com/kralicky/ttr/pkg/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDiscord is an in-process Discord IPC server.
+type fakeDiscord struct {
+	path string
+	// if set, SET_ACTIVITY commands fail with this message
+	errorMessage string
+
+	mu         sync.Mutex
+	clientIds  []string
+	activities []*discord.Activity
+}
+
+func newFakeDiscord(t *testing.T) *fakeDiscord {
+	t.Helper()
+	// unix socket paths are limited to ~100 characters, which t.TempDir
+	// can exceed
+	dir, err := os.MkdirTemp("", "discord")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	f := &fakeDiscord{path: filepath.Join(dir, "discord-ipc-0")}
+	l, err := net.Listen("unix", f.path)
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeDiscord) serve(conn net.Conn) {
+	defer conn.Close()
+	op, payload, err := discord.ReadFrame(conn)
+	if err != nil || op != discord.OpHandshake {
+		return
+	}
+	var hs struct {
+		ClientId string `json:"client_id"`
+	}
+	json.Unmarshal(payload, &hs)
+	f.mu.Lock()
+	f.clientIds = append(f.clientIds, hs.ClientId)
+	f.mu.Unlock()
+	ready := "READY"
+	discord.WriteFrame(conn, discord.OpFrame, discord.Message{Cmd: "DISPATCH", Evt: &ready})
+
+	for {
+		op, payload, err := discord.ReadFrame(conn)
+		if err != nil {
+			return
+		}
+		if op != discord.OpFrame {
+			continue
+		}
+		var msg discord.Message
+		json.Unmarshal(payload, &msg)
+		// check that pings are answered
+		discord.WriteFrame(conn, discord.OpPing, map[string]any{})
+		if op, _, err := discord.ReadFrame(conn); err != nil || op != discord.OpPong {
+			return
+		}
+		resp := discord.Message{Cmd: msg.Cmd, Nonce: msg.Nonce}
+		if f.errorMessage != "" {
+			evt := "ERROR"
+			resp.Evt = &evt
+			resp.Data, _ = json.Marshal(map[string]any{"code": 4000, "message": f.errorMessage})
+		} else {
+			var args struct {
+				Pid      int               `json:"pid"`
+				Activity *discord.Activity `json:"activity"`
+			}
+			json.Unmarshal(msg.Args, &args)
+			f.mu.Lock()
+			f.activities = append(f.activities, args.Activity)
+			f.mu.Unlock()
+		}
+		discord.WriteFrame(conn, discord.OpFrame, resp)
+	}
+}
+
+func (f *fakeDiscord) lastActivity() (*discord.Activity, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.activities) == 0 {
+		return nil, 0
+	}
+	return f.activities[len(f.activities)-1], len(f.activities)
+}
+
+func TestSetActivity(t *testing.T) {
+	f := newFakeDiscord(t)
+	ctx, ca := context.WithTimeout(context.Background(), 5*time.Second)
+	defer ca()
+
+	conn, err := discord.Dial(ctx, "1234", discord.WithSocketPath(f.path))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	activity := &discord.Activity{
+		Details:    "Bullion Mint",
+		State:      "Cashbot HQ",
+		Timestamps: &discord.Timestamps{Start: 1718042400000},
+	}
+	require.NoError(t, conn.SetActivity(ctx, activity))
+	require.NoError(t, conn.SetActivity(ctx, nil))
+
+	assert.Equal(t, []string{"1234"}, f.clientIds)
+	assert.Equal(t, []*discord.Activity{activity, nil}, f.activities)
+}
+
+func TestSetActivityError(t *testing.T) {
+	f := newFakeDiscord(t)
+	f.errorMessage = "invalid client id"
+	conn, err := discord.Dial(context.Background(), "1234", discord.WithSocketPath(f.path))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	err = conn.SetActivity(context.Background(), &discord.Activity{Details: "test"})
+	assert.ErrorContains(t, err, "invalid client id")
+}
+
+func TestDialNotRunning(t *testing.T) {
+	_, err := discord.Dial(context.Background(), "1234", discord.WithSocketPath(filepath.Join(t.TempDir(), "discord-ipc-0")))
+	assert.True(t, errors.Is(err, discord.ErrNotRunning))
+}
+
+type fakeGame struct {
+	tracker *game.StatusTracker
+	w       *io.PipeWriter
+	done    chan struct{}
+}
+
+func startGame(p *discord.Presence, account string) *fakeGame {
+	r, w := io.Pipe()
+	g := &fakeGame{tracker: game.NewStatusTracker(r), w: w, done: make(chan struct{})}
+	p.Attach(account, g.tracker)
+	go func() {
+		defer close(g.done)
+		g.tracker.Run()
+	}()
+	return g
+}
+
+func (g *fakeGame) enter(line string) {
+	g.w.Write([]byte(line + "\n"))
+}
+
+func (g *fakeGame) stop() {
+	g.w.Close()
+	<-g.done
+}
+
+const (
+	mintLine   = `:vlt: enter(requestStatus={'loader': 'cogHQLoader', 'where': 'mintInterior', 'how': 'teleportIn', 'zoneId': 12701, 'mintId': 12700, 'hoodId': 12000})`
+	ttcLine    = `:vlt: enter(requestStatus={'loader': 'safeZoneLoader', 'where': 'playground', 'how': 'teleportIn', 'hoodId': 2000, 'zoneId': 2000})`
+	streetLine = `:vlt: enter(requestStatus={'loader': 'townLoader', 'where': 'street', 'how': 'walk', 'hoodId': 2000, 'zoneId': 2213})`
+)
+
+func TestPresence(t *testing.T) {
+	f := newFakeDiscord(t)
+	p := discord.NewPresence(discord.PresenceOptions{
+		ClientId:         "1234",
+		DisabledAccounts: []string{"secret"},
+		Dial:             []discord.DialOption{discord.WithSocketPath(f.path)},
+	})
+	defer p.Close()
+
+	waitFor := func(count int) *discord.Activity {
+		t.Helper()
+		var activity *discord.Activity
+		require.Eventually(t, func() bool {
+			var n int
+			activity, n = f.lastActivity()
+			return n == count
+		}, 5*time.Second, 10*time.Millisecond)
+		return activity
+	}
+
+	toon1 := startGame(p, "toon1")
+	toon1.enter(mintLine)
+	activity := waitFor(1)
+	assert.Equal(t, "Bullion Mint", activity.Details)
+	assert.Equal(t, "Cashbot HQ", activity.State)
+	assert.InDelta(t, time.Now().UnixMilli(), activity.Timestamps.Start, 5000)
+
+	toon2 := startGame(p, "toon2")
+	toon2.enter(ttcLine)
+	activity = waitFor(2)
+	assert.Equal(t, "Toontown Central", activity.Details)
+	assert.Equal(t, "2 toons running", activity.State)
+
+	toon2.enter(streetLine)
+	activity = waitFor(3)
+	assert.Equal(t, "Loopy Lane", activity.Details)
+	assert.Equal(t, "Toontown Central · 2 toons running", activity.State)
+
+	// disabled accounts are not shown or counted
+	secret := startGame(p, "secret")
+	secret.enter(ttcLine)
+	secret.stop()
+
+	// the most recent remaining toon is shown
+	toon2.stop()
+	activity = waitFor(4)
+	assert.Equal(t, "Bullion Mint", activity.Details)
+	assert.Equal(t, "Cashbot HQ", activity.State)
+
+	// the activity is cleared once no toons are running
+	toon1.stop()
+	activity = waitFor(5)
+	assert.Nil(t, activity)
+}
+
+func TestPresenceStalled(t *testing.T) {
+	// a Discord client that accepts connections but never answers
+	dir, err := os.MkdirTemp("", "discord")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "discord-ipc-0")
+	l, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	defer l.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := l.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	p := discord.NewPresence(discord.PresenceOptions{
+		ClientId: "1234",
+		Dial:     []discord.DialOption{discord.WithSocketPath(path)},
+	})
+	toon := startGame(p, "toon1")
+	toon.enter(mintLine)
+	conn := <-accepted
+
+	// the activity is still available while connecting to Discord
+	require.Eventually(t, func() bool {
+		activity := p.Activity()
+		return activity != nil && activity.Details == "Bullion Mint"
+	}, time.Second, 10*time.Millisecond)
+
+	conn.Close()
+	toon.stop()
+	p.Close()
+}
@@ -0,0 +1,261 @@
+// Package discord sets Discord Rich Presence activity over the local Discord
+// IPC socket.
+package discord
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type Opcode uint32
+
+const (
+	OpHandshake Opcode = 0
+	OpFrame     Opcode = 1
+	OpClose     Opcode = 2
+	OpPing      Opcode = 3
+	OpPong      Opcode = 4
+)
+
+// maxFrameSize limits the size of frames read from the socket.
+const maxFrameSize = 64 * 1024
+
+// ReadFrame reads a frame: a little-endian opcode and payload length,
+// followed by a JSON payload.
+func ReadFrame(r io.Reader) (Opcode, []byte, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	op := Opcode(binary.LittleEndian.Uint32(header[0:4]))
+	length := binary.LittleEndian.Uint32(header[4:8])
+	if length > maxFrameSize {
+		return 0, nil, fmt.Errorf("frame too large (%d bytes)", length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return op, payload, nil
+}
+
+// WriteFrame encodes v as JSON and writes it as a frame.
+func WriteFrame(w io.Writer, op Opcode, v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 8, 8+len(payload))
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(op))
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(len(payload)))
+	_, err = w.Write(append(buf, payload...))
+	return err
+}
+
+// Message is the payload of a frame.
+type Message struct {
+	Cmd   string          `json:"cmd"`
+	Evt   *string         `json:"evt,omitempty"`
+	Nonce string          `json:"nonce,omitempty"`
+	Args  json.RawMessage `json:"args,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+type handshake struct {
+	Version  int    `json:"v"`
+	ClientId string `json:"client_id"`
+}
+
+type errorData struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// SocketPaths returns the paths where the Discord IPC socket may be found.
+// Discord listens on the first free discord-ipc-<n> socket, for n from 0
+// to 9, in the runtime or temp directory (or in the sandbox directory of the
+// flatpak or snap package).
+func SocketPaths() []string {
+	var dirs []string
+	for _, env := range []string{"XDG_RUNTIME_DIR", "TMPDIR", "TMP", "TEMP"} {
+		if dir := os.Getenv(env); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	dirs = append(dirs, "/tmp")
+	var paths []string
+	for _, dir := range dirs {
+		for _, sub := range []string{"", "app/com.discordapp.Discord", "snap.discord"} {
+			for i := range 10 {
+				paths = append(paths, filepath.Join(dir, sub, fmt.Sprintf("discord-ipc-%d", i)))
+			}
+		}
+	}
+	return paths
+}
+
+// Conn is a connection to the Discord client.
+type Conn struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+type DialOptions struct {
+	socketPaths []string
+}
+
+type DialOption func(*DialOptions)
+
+func (o *DialOptions) apply(opts ...DialOption) {
+	for _, op := range opts {
+		op(o)
+	}
+}
+
+// WithSocketPath connects to the socket at the given path, instead of
+// searching SocketPaths.
+func WithSocketPath(path string) DialOption {
+	return func(o *DialOptions) {
+		o.socketPaths = []string{path}
+	}
+}
+
+// ErrNotRunning is returned by Dial if no Discord IPC socket was found.
+var ErrNotRunning = errors.New("discord is not running")
+
+// Dial connects to the Discord client and completes the handshake for the
+// application with the given client ID.
+func Dial(ctx context.Context, clientId string, opts ...DialOption) (*Conn, error) {
+	options := DialOptions{
+		socketPaths: SocketPaths(),
+	}
+	options.apply(opts...)
+
+	var d net.Dialer
+	for _, path := range options.socketPaths {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		nc, err := d.DialContext(ctx, "unix", path)
+		if err != nil {
+			continue
+		}
+		c := &Conn{conn: nc}
+		if err := c.handshake(ctx, clientId); err != nil {
+			nc.Close()
+			return nil, err
+		}
+		return c, nil
+	}
+	return nil, ErrNotRunning
+}
+
+func (c *Conn) handshake(ctx context.Context, clientId string) error {
+	c.setDeadline(ctx)
+	if err := WriteFrame(c.conn, OpHandshake, handshake{Version: 1, ClientId: clientId}); err != nil {
+		return err
+	}
+	msg, err := c.readMessage()
+	if err != nil {
+		return fmt.Errorf("discord handshake failed: %w", err)
+	}
+	if msg.Evt == nil || *msg.Evt != "READY" {
+		return fmt.Errorf("discord handshake failed: unexpected response %q", msg.Cmd)
+	}
+	return nil
+}
+
+func (c *Conn) setDeadline(ctx context.Context) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(10 * time.Second)
+	}
+	c.conn.SetDeadline(deadline)
+}
+
+// readMessage reads the next message, answering pings. A close frame is
+// returned as an error.
+func (c *Conn) readMessage() (*Message, error) {
+	for {
+		op, payload, err := ReadFrame(c.conn)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case OpPing:
+			if err := WriteFrame(c.conn, OpPong, json.RawMessage(payload)); err != nil {
+				return nil, err
+			}
+		case OpClose:
+			var data errorData
+			json.Unmarshal(payload, &data)
+			return nil, fmt.Errorf("connection closed by discord: %s (%d)", data.Message, data.Code)
+		case OpFrame:
+			var msg Message
+			if err := json.Unmarshal(payload, &msg); err != nil {
+				return nil, err
+			}
+			return &msg, nil
+		}
+	}
+}
+
+// call sends a command and waits for its response.
+func (c *Conn) call(ctx context.Context, cmd string, args any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setDeadline(ctx)
+
+	argData, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, 8)
+	rand.Read(nonce)
+	req := Message{Cmd: cmd, Nonce: hex.EncodeToString(nonce), Args: argData}
+	if err := WriteFrame(c.conn, OpFrame, req); err != nil {
+		return err
+	}
+	for {
+		msg, err := c.readMessage()
+		if err != nil {
+			return err
+		}
+		if msg.Nonce != req.Nonce {
+			continue
+		}
+		if msg.Evt != nil && *msg.Evt == "ERROR" {
+			var data errorData
+			json.Unmarshal(msg.Data, &data)
+			return fmt.Errorf("discord error: %s (%d)", data.Message, data.Code)
+		}
+		return nil
+	}
+}
+
+// SetActivity sets the activity shown on the user's profile. If activity is
+// nil, the activity is cleared.
+func (c *Conn) SetActivity(ctx context.Context, activity *Activity) error {
+	return c.call(ctx, "SET_ACTIVITY", struct {
+		Pid      int       `json:"pid"`
+		Activity *Activity `json:"activity"`
+	}{
+		Pid:      os.Getpid(),
+		Activity: activity,
+	})
+}
+
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,177 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/kralicky/ttr/pkg/game"
+	"github.com/kralicky/ttr/pkg/zones"
+	log "github.com/sirupsen/logrus"
+)
+
+const callTimeout = 5 * time.Second
+
+type PresenceOptions struct {
+	// ID of the Discord application the activity is shown for.
+	ClientId string
+	// Accounts that are never shown or counted.
+	DisabledAccounts []string
+	// Key of the application's art asset to show with the activity, if any.
+	LargeImage string
+	Dial       []DialOption
+}
+
+// Presence shows the zone of the running toons as Discord activity. Since
+// only one activity can be shown, it shows the toon that most recently
+// changed zones, along with the number of toons running.
+type Presence struct {
+	opts PresenceOptions
+
+	mu    sync.Mutex
+	toons map[string]*toonZone
+
+	// ipcMu serializes calls to Discord, so that they don't hold up mu
+	ipcMu sync.Mutex
+	conn  *Conn
+}
+
+type toonZone struct {
+	location string
+	hood     string
+	since    time.Time
+}
+
+func NewPresence(opts PresenceOptions) *Presence {
+	return &Presence{
+		opts:  opts,
+		toons: map[string]*toonZone{},
+	}
+}
+
+// Attach shows the toon's zone until the tracker stops. It can be used as a
+// game.WithTrackerHook.
+func (p *Presence) Attach(account string, tracker *game.StatusTracker) {
+	if slices.Contains(p.opts.DisabledAccounts, account) {
+		return
+	}
+	// only the latest zone matters
+	sub := tracker.Subscribe(game.SubscribeOptions{
+		Buffer: 1,
+		Policy: game.PolicyCoalesce,
+		Filter: game.ZonesOnly,
+	})
+	p.mu.Lock()
+	p.toons[account] = &toonZone{}
+	p.mu.Unlock()
+
+	go func() {
+		for ev := range sub.C {
+			tz := &toonZone{
+				location: game.LocationName(ev.Zone),
+				since:    time.Now(),
+			}
+			if ev.Zone.HoodId != nil {
+				tz.hood = zones.HoodName(*ev.Zone.HoodId)
+			}
+			p.mu.Lock()
+			p.toons[account] = tz
+			p.mu.Unlock()
+			p.update()
+		}
+		p.mu.Lock()
+		delete(p.toons, account)
+		p.mu.Unlock()
+		p.update()
+	}()
+}
+
+// Activity returns the activity for the current toons, or nil if no toons
+// are in a zone.
+func (p *Presence) Activity() *Activity {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.activityLocked()
+}
+
+func (p *Presence) activityLocked() *Activity {
+	var latest *toonZone
+	for _, tz := range p.toons {
+		if tz.location != "" && (latest == nil || tz.since.After(latest.since)) {
+			latest = tz
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	activity := &Activity{
+		Details:    latest.location,
+		Timestamps: &Timestamps{Start: latest.since.UnixMilli()},
+	}
+	if latest.hood != latest.location {
+		activity.State = latest.hood
+	}
+	if n := len(p.toons); n > 1 {
+		running := fmt.Sprintf("%d toons running", n)
+		if activity.State == "" {
+			activity.State = running
+		} else {
+			activity.State += " · " + running
+		}
+	}
+	if p.opts.LargeImage != "" {
+		activity.Assets = &Assets{
+			LargeImage: p.opts.LargeImage,
+			LargeText:  "Toontown Rewritten",
+		}
+	}
+	return activity
+}
+
+// update sends the current activity to Discord, connecting first if needed.
+// Errors are logged, since the activity is only informational; if the
+// connection fails, it is retried on the next update.
+func (p *Presence) update() {
+	p.ipcMu.Lock()
+	defer p.ipcMu.Unlock()
+	// read the activity once it is this update's turn, so that the last
+	// update to finish sends the latest activity
+	activity := p.Activity()
+	ctx, ca := context.WithTimeout(context.Background(), callTimeout)
+	defer ca()
+	if p.conn == nil {
+		conn, err := Dial(ctx, p.opts.ClientId, p.opts.Dial...)
+		if err != nil {
+			if errors.Is(err, ErrNotRunning) {
+				log.Debug("discord: ", err)
+			} else {
+				log.Warnf("discord: %v", err)
+			}
+			return
+		}
+		p.conn = conn
+	}
+	if err := p.conn.SetActivity(ctx, activity); err != nil {
+		log.Warnf("discord: failed to set activity: %v", err)
+		p.conn.Close()
+		p.conn = nil
+	}
+}
+
+// Close clears the activity and disconnects from Discord.
+func (p *Presence) Close() error {
+	p.ipcMu.Lock()
+	defer p.ipcMu.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	ctx, ca := context.WithTimeout(context.Background(), callTimeout)
+	defer ca()
+	p.conn.SetActivity(ctx, nil)
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
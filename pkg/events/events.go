@@ -0,0 +1,96 @@
+// Package events publishes game events (zone changes, process lifecycle and
+// logins) as newline-delimited JSON to files, unix sockets or webhooks.
+package events
+
+import (
+	"time"
+
+	"github.com/kralicky/ttr/pkg/game"
+	"github.com/kralicky/ttr/pkg/zones"
+)
+
+type Type string
+
+const (
+	TypeZone           Type = "zone"
+	TypeProcessStarted Type = "processStarted"
+	TypeProcessExited  Type = "processExited"
+	TypeProcessCrashed Type = "processCrashed"
+	TypeLogin          Type = "login"
+	TypeLoginFailed    Type = "loginFailed"
+)
+
+// Event is a single line of the event stream.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Type    Type      `json:"type"`
+	Account string    `json:"account"`
+
+	// Set for zone events.
+	Location string                   `json:"location,omitempty"`
+	Hood     string                   `json:"hood,omitempty"`
+	Zone     *game.EnterRequestStatus `json:"zone,omitempty"`
+
+	// Set for process events.
+	Pid     int    `json:"pid,omitempty"`
+	LogFile string `json:"logFile,omitempty"`
+	// Set for exited and crashed processes.
+	ExitCode *int `json:"exitCode,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+func NewZoneEvent(account string, zone *game.ActiveZone) Event {
+	req := zone.Request
+	ev := Event{
+		Time:     time.Now(),
+		Type:     TypeZone,
+		Account:  account,
+		Location: zone.String(),
+		Zone:     &req,
+	}
+	if req.HoodId != nil {
+		ev.Hood = zones.HoodName(*req.HoodId)
+	}
+	return ev
+}
+
+func NewProcessEvent(pe game.ProcessEvent) Event {
+	ev := Event{
+		Time:    time.Now(),
+		Account: pe.Account,
+		Pid:     pe.Pid,
+		LogFile: pe.LogFile,
+	}
+	switch pe.State {
+	case game.ProcessStarted:
+		ev.Type = TypeProcessStarted
+	case game.ProcessCrashed:
+		ev.Type = TypeProcessCrashed
+	default:
+		ev.Type = TypeProcessExited
+	}
+	if pe.State != game.ProcessStarted {
+		code := pe.ExitCode
+		ev.ExitCode = &code
+	}
+	if pe.Err != nil {
+		ev.Error = pe.Err.Error()
+	}
+	return ev
+}
+
+// NewLoginEvent returns a login event, or a failed login event if err is
+// not nil.
+func NewLoginEvent(account string, err error) Event {
+	ev := Event{
+		Time:    time.Now(),
+		Type:    TypeLogin,
+		Account: account,
+	}
+	if err != nil {
+		ev.Type = TypeLoginFailed
+		ev.Error = err.Error()
+	}
+	return ev
+}
@@ -0,0 +1,213 @@
+package events_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kralicky/ttr/pkg/events"
+	"github.com/kralicky/ttr/pkg/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mintLine = `:vlt: enter(requestStatus={'loader': 'cogHQLoader', 'where': 'mintInterior', 'how': 'teleportIn', 'zoneId': 12701, 'mintId': 12700, 'hoodId': 12000})`
+
+func readEvents(t *testing.T, r io.Reader) []events.Event {
+	t.Helper()
+	var list []events.Event
+	scan := bufio.NewScanner(r)
+	for scan.Scan() {
+		var ev events.Event
+		require.NoError(t, json.Unmarshal(scan.Bytes(), &ev))
+		list = append(list, ev)
+	}
+	return list
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	sink, err := events.ParseSink("file://" + path)
+	require.NoError(t, err)
+	p := events.NewPublisher(sink)
+
+	p.Publish(events.NewLoginEvent("toon1", nil))
+	r, w := io.Pipe()
+	tracker := game.NewStatusTracker(r)
+	p.Attach("toon1", tracker)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tracker.Run()
+	}()
+	w.Write([]byte(mintLine + "\nsome log line\n"))
+	w.Close()
+	<-done
+	// zone events are published in the background
+	require.Eventually(t, func() bool {
+		data, _ := os.ReadFile(path)
+		return strings.Contains(string(data), `"type":"zone"`)
+	}, 5*time.Second, 10*time.Millisecond)
+	p.Publish(events.NewLoginEvent("toon2", errors.New("bad password")))
+	require.NoError(t, p.Close())
+	// events published after closing are ignored
+	p.Publish(events.NewLoginEvent("toon3", nil))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	list := readEvents(t, f)
+	require.Len(t, list, 3)
+	assert.Equal(t, events.TypeLogin, list[0].Type)
+	assert.Equal(t, "toon1", list[0].Account)
+	assert.Equal(t, events.TypeZone, list[1].Type)
+	assert.Equal(t, "Bullion Mint", list[1].Location)
+	assert.Equal(t, "Cashbot HQ", list[1].Hood)
+	assert.Equal(t, "mintInterior", list[1].Zone.Where)
+	assert.Equal(t, events.TypeLoginFailed, list[2].Type)
+	assert.Equal(t, "bad password", list[2].Error)
+	assert.False(t, list[2].Time.IsZero())
+}
+
+func TestUnixSink(t *testing.T) {
+	// unix socket paths are limited to ~100 characters, which t.TempDir
+	// can exceed
+	dir, err := os.MkdirTemp("", "events")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.sock")
+
+	l, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	defer l.Close()
+	lines := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			// read one line per connection, to test reconnecting
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			lines <- line
+			conn.Close()
+		}
+	}()
+
+	sink, err := events.ParseSink("unix:" + path)
+	require.NoError(t, err)
+	defer sink.Close()
+	ctx := context.Background()
+	require.NoError(t, sink.Send(ctx, events.NewLoginEvent("toon1", nil)))
+	assert.Contains(t, <-lines, `"account":"toon1"`)
+
+	// the first write after the listener closes the connection may succeed,
+	// so send until the second line arrives
+	deadline := time.After(5 * time.Second)
+	for {
+		sink.Send(ctx, events.NewLoginEvent("toon2", nil))
+		select {
+		case line := <-lines:
+			assert.Contains(t, line, `"account":"toon2"`)
+			return
+		case <-deadline:
+			t.Fatal("timed out waiting for reconnect")
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var attempts atomic.Int32
+	bodies := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+		switch attempts.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	sink := events.NewWebhookSink(srv.URL, events.WebhookOptions{Backoff: time.Millisecond})
+	require.NoError(t, sink.Send(context.Background(), events.NewLoginEvent("toon1", nil)))
+	assert.EqualValues(t, 3, attempts.Load())
+	body := <-bodies
+	assert.True(t, strings.HasSuffix(body, "}\n"))
+	assert.Equal(t, 1, strings.Count(body, "\n"))
+}
+
+func TestWebhookSinkErrors(t *testing.T) {
+	var attempts atomic.Int32
+	status := http.StatusBadRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	// client errors are not retried
+	sink := events.NewWebhookSink(srv.URL, events.WebhookOptions{Backoff: time.Millisecond})
+	err := sink.Send(context.Background(), events.NewLoginEvent("toon1", nil))
+	assert.ErrorContains(t, err, "400")
+	assert.EqualValues(t, 1, attempts.Load())
+
+	// server errors are retried up to MaxAttempts
+	attempts.Store(0)
+	status = http.StatusInternalServerError
+	sink = events.NewWebhookSink(srv.URL, events.WebhookOptions{MaxAttempts: 3, Backoff: time.Millisecond})
+	err = sink.Send(context.Background(), events.NewLoginEvent("toon1", nil))
+	assert.ErrorContains(t, err, "after 3 attempt(s)")
+	assert.EqualValues(t, 3, attempts.Load())
+}
+
+func TestParseSink(t *testing.T) {
+	s, err := events.ParseSink("https://example.com/hook")
+	require.NoError(t, err)
+	assert.IsType(t, &events.WebhookSink{}, s)
+
+	s, err = events.ParseSink("unix:///run/ttr.sock")
+	require.NoError(t, err)
+	assert.IsType(t, &events.UnixSink{}, s)
+
+	s, err = events.ParseSink(filepath.Join(t.TempDir(), "events.ndjson"))
+	require.NoError(t, err)
+	assert.IsType(t, &events.FileSink{}, s)
+	s.Close()
+
+	_, err = events.ParseSink("unix:")
+	assert.Error(t, err)
+}
+
+func TestNewProcessEvent(t *testing.T) {
+	ev := events.NewProcessEvent(game.ProcessEvent{Account: "toon1", State: game.ProcessStarted, Pid: 42})
+	assert.Equal(t, events.TypeProcessStarted, ev.Type)
+	assert.Nil(t, ev.ExitCode)
+
+	ev = events.NewProcessEvent(game.ProcessEvent{Account: "toon1", State: game.ProcessCrashed, Pid: 42, ExitCode: 139, Err: errors.New("exit status 139")})
+	assert.Equal(t, events.TypeProcessCrashed, ev.Type)
+	require.NotNil(t, ev.ExitCode)
+	assert.Equal(t, 139, *ev.ExitCode)
+	assert.Equal(t, "exit status 139", ev.Error)
+
+	ev = events.NewProcessEvent(game.ProcessEvent{Account: "toon1", State: game.ProcessExited})
+	assert.Equal(t, events.TypeProcessExited, ev.Type)
+	assert.Equal(t, 0, *ev.ExitCode)
+}
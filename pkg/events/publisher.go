@@ -0,0 +1,149 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kralicky/ttr/pkg/game"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	queueSize = 1024
+	// time limit for sending a single event, including retries
+	sendTimeout = 2 * time.Minute
+	// time to wait for queued events to be sent when closing
+	closeTimeout = 10 * time.Second
+)
+
+// Publisher sends events to its sinks in the background. Each sink has its
+// own queue, so that a slow sink (e.g. a webhook being retried) does not
+// hold up the others. If a sink's queue is full, new events are dropped.
+type Publisher struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers []*worker
+	wg      sync.WaitGroup
+
+	mu     sync.Mutex
+	closed bool
+}
+
+type worker struct {
+	sink    Sink
+	queue   chan Event
+	mu      sync.Mutex
+	dropped uint64
+}
+
+func NewPublisher(sinks ...Sink) *Publisher {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Publisher{ctx: ctx, cancel: cancel}
+	for _, s := range sinks {
+		w := &worker{sink: s, queue: make(chan Event, queueSize)}
+		p.workers = append(p.workers, w)
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.run(w)
+		}()
+	}
+	return p
+}
+
+func (p *Publisher) run(w *worker) {
+	for ev := range w.queue {
+		ctx, ca := context.WithTimeout(p.ctx, sendTimeout)
+		err := w.sink.Send(ctx, ev)
+		ca()
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Warnf("failed to send %s event: %v", ev.Type, err)
+		}
+	}
+}
+
+// Publish queues an event to be sent to every sink. It does not block.
+func (p *Publisher) Publish(ev Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	for _, w := range p.workers {
+		select {
+		case w.queue <- ev:
+		default:
+			w.mu.Lock()
+			w.dropped++
+			w.mu.Unlock()
+		}
+	}
+}
+
+// Dropped returns the number of events dropped because a sink's queue was
+// full, summed over all sinks.
+func (p *Publisher) Dropped() uint64 {
+	var total uint64
+	for _, w := range p.workers {
+		w.mu.Lock()
+		total += w.dropped
+		w.mu.Unlock()
+	}
+	return total
+}
+
+// Attach publishes the zone changes of a game. It can be used as a
+// game.WithTrackerHook.
+func (p *Publisher) Attach(account string, tracker *game.StatusTracker) {
+	sub := tracker.Subscribe(game.SubscribeOptions{Filter: game.ZonesOnly})
+	go func() {
+		for zone := range sub.Zones() {
+			p.Publish(NewZoneEvent(account, zone))
+		}
+	}()
+}
+
+// PublishProcessEvent publishes a game process lifecycle event. It can be
+// used as a game.WithLifecycleHook.
+func (p *Publisher) PublishProcessEvent(ev game.ProcessEvent) {
+	p.Publish(NewProcessEvent(ev))
+}
+
+// Close sends the queued events and closes the sinks. If the events can't
+// be sent within a few seconds, they are discarded.
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	for _, w := range p.workers {
+		close(w.queue)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(closeTimeout):
+		p.cancel()
+		<-done
+	}
+	p.cancel()
+
+	if dropped := p.Dropped(); dropped > 0 {
+		log.Warnf("%d events were dropped", dropped)
+	}
+	var errs []error
+	for _, w := range p.workers {
+		errs = append(errs, w.sink.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,217 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sink receives events. Send is never called concurrently.
+type Sink interface {
+	Send(ctx context.Context, ev Event) error
+	Close() error
+}
+
+func encodeLine(ev Event) ([]byte, error) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// FileSink appends events to a file.
+type FileSink struct {
+	f *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{f: f}, nil
+}
+
+func (s *FileSink) Send(_ context.Context, ev Event) error {
+	line, err := encodeLine(ev)
+	if err != nil {
+		return err
+	}
+	_, err = s.f.Write(line)
+	return err
+}
+
+func (s *FileSink) Close() error {
+	return s.f.Close()
+}
+
+// UnixSink writes events to a unix stream socket. The socket is connected
+// when the first event is sent, and reconnected if a write fails, so the
+// listener does not need to be running when the game starts.
+type UnixSink struct {
+	path string
+	conn net.Conn
+}
+
+func NewUnixSink(path string) *UnixSink {
+	return &UnixSink{path: path}
+}
+
+func (s *UnixSink) Send(ctx context.Context, ev Event) error {
+	line, err := encodeLine(ev)
+	if err != nil {
+		return err
+	}
+	// if the existing connection was closed by the listener, try again
+	// with a new connection
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.conn == nil {
+			var d net.Dialer
+			s.conn, err = d.DialContext(ctx, "unix", s.path)
+			if err != nil {
+				return err
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			s.conn.SetWriteDeadline(deadline)
+		}
+		if _, err = s.conn.Write(line); err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+	return err
+}
+
+func (s *UnixSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
+
+type WebhookOptions struct {
+	// Number of attempts to send each event. Defaults to 5.
+	MaxAttempts int
+	// Delay before the first retry, doubled for each retry after that.
+	// Defaults to 1s.
+	Backoff time.Duration
+	// Maximum delay between retries. Defaults to 30s.
+	MaxBackoff time.Duration
+	Client     *http.Client
+}
+
+// WebhookSink POSTs each event to a URL, as a single line of NDJSON.
+// Requests are retried with exponential backoff if they fail, or if the
+// server responds with 429 or a 5xx status.
+type WebhookSink struct {
+	url  string
+	opts WebhookOptions
+}
+
+func NewWebhookSink(url string, opts WebhookOptions) *WebhookSink {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 30 * time.Second
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &WebhookSink{url: url, opts: opts}
+}
+
+func (s *WebhookSink) Send(ctx context.Context, ev Event) error {
+	line, err := encodeLine(ev)
+	if err != nil {
+		return err
+	}
+	backoff := s.opts.Backoff
+	for attempt := 1; ; attempt++ {
+		retryAfter, err := s.post(ctx, line)
+		if err == nil {
+			return nil
+		}
+		if retryAfter < 0 || attempt == s.opts.MaxAttempts {
+			return fmt.Errorf("webhook failed after %d attempt(s): %w", attempt, err)
+		}
+		delay := backoff
+		if retryAfter > 0 {
+			delay = retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("webhook failed after %d attempt(s): %w", attempt, err)
+		case <-time.After(min(delay, s.opts.MaxBackoff)):
+		}
+		backoff = min(backoff*2, s.opts.MaxBackoff)
+	}
+}
+
+// post sends one request. If it fails, retryAfter is negative if the
+// request should not be retried, and otherwise the delay requested by the
+// server (or 0 for the default backoff).
+func (s *WebhookSink) post(ctx context.Context, body []byte) (retryAfter time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	switch {
+	case resp.StatusCode/100 == 2:
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5:
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			retryAfter = time.Duration(secs) * time.Second
+		}
+		return retryAfter, fmt.Errorf("unexpected status: %s", resp.Status)
+	default:
+		return -1, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+}
+
+func (s *WebhookSink) Close() error {
+	return nil
+}
+
+// ParseSink returns the sink for a destination: an http or https URL for a
+// webhook, unix:<path> for a unix socket, or a file path (optionally
+// prefixed with file:).
+func ParseSink(dest string) (Sink, error) {
+	switch {
+	case strings.HasPrefix(dest, "http://"), strings.HasPrefix(dest, "https://"):
+		return NewWebhookSink(dest, WebhookOptions{}), nil
+	case strings.HasPrefix(dest, "unix:"):
+		path := strings.TrimPrefix(strings.TrimPrefix(dest, "unix:"), "//")
+		if path == "" {
+			return nil, fmt.Errorf("invalid event sink %q: missing socket path", dest)
+		}
+		return NewUnixSink(path), nil
+	default:
+		path := strings.TrimPrefix(strings.TrimPrefix(dest, "file:"), "//")
+		if path == "" {
+			return nil, fmt.Errorf("invalid event sink %q: missing file path", dest)
+		}
+		return NewFileSink(path)
+	}
+}
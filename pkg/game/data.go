@@ -10,6 +10,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 
 	"github.com/gabstv/go-bsdiff/pkg/bspatch"
 	"github.com/kralicky/ttr/pkg/api"
@@ -34,18 +35,22 @@ func UpsertDataDir() (string, error) {
 	return dir, os.MkdirAll(dir, 0755)
 }
 
-func SyncGameData(ctx context.Context, client api.DownloadClient) error {
+// SyncGameData downloads any game files that are missing or out of date, and
+// reports whether any files were updated.
+func SyncGameData(ctx context.Context, client api.DownloadClient) (bool, error) {
 	log.Debug("syncing game data")
 	patchManifest, err := client.DownloadPatchManifest(ctx)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	dataDir, err := DataDir()
 	if err != nil {
-		return err
+		return false, err
 	}
 
+	var updated atomic.Bool
+
 	eg, ctx := errgroup.WithContext(ctx)
 	for filename, spec := range patchManifest {
 		if !ShouldDownload(spec) {
@@ -83,6 +88,7 @@ func SyncGameData(ctx context.Context, client api.DownloadClient) error {
 			if err != nil {
 				return fmt.Errorf("error opening file %s for writing: %w", filename, err)
 			}
+			updated.Store(true)
 			// check if there is a known patch available for the file we have
 			if p, ok := spec.Patches[sum]; ok {
 				return fetchAndPatchFile(ctx, client, filename, spec, p, f)
@@ -92,7 +98,10 @@ func SyncGameData(ctx context.Context, client api.DownloadClient) error {
 		})
 	}
 
-	return eg.Wait()
+	if err := eg.Wait(); err != nil {
+		return false, err
+	}
+	return updated.Load(), nil
 }
 
 func fetchAndUpdateFile(
@@ -12,17 +12,28 @@ import (
 	"time"
 
 	"github.com/kralicky/ttr/pkg/api"
+	"github.com/kralicky/ttr/pkg/gameenv"
 	log "github.com/sirupsen/logrus"
 )
 
-func LaunchProcess(ctx context.Context, creds *api.LoginSuccessPayload) error {
+// LogsDir returns the directory containing the game log files. Log files are
+// named ttr-<unix timestamp>.log, after the time the game was started.
+func LogsDir() (string, error) {
 	dir, err := DataDir()
 	if err != nil {
-		return err
+		return "", err
+	}
+	return filepath.Join(dir, "logs"), nil
+}
+
+// createLogFile creates a new log file for a game process.
+func createLogFile() (*os.File, error) {
+	logsDir, err := LogsDir()
+	if err != nil {
+		return nil, err
 	}
-	logsDir := filepath.Join(dir, "logs")
 	if err := os.MkdirAll(logsDir, 0o755); err != nil {
-		return err
+		return nil, err
 	}
 
 	timestamp := time.Now().Unix()
@@ -39,27 +50,147 @@ func LaunchProcess(ctx context.Context, creds *api.LoginSuccessPayload) error {
 	// open the log file for writing
 	f, err := os.Create(logFile)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer f.Close()
 	log.Infof("writing logs to %s", logFile)
+	return f, nil
+}
 
-	statusR, statusW := io.Pipe()
-	statusTracker := NewStatusTracker(statusR)
-	go statusTracker.Run()
-	go RunMintInfoManager(statusTracker)
-
-	logWriter := io.MultiWriter(f, statusW)
-
+// gameCommand returns the command used to run the game engine with the given
+// credentials, with its output written to logWriter.
+func gameCommand(ctx context.Context, account string, creds *api.LoginSuccessPayload, logWriter io.Writer) (*exec.Cmd, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return nil, err
+	}
 	binary := filepath.Join(dir, Executable)
 	// ensure the file is executable
 	if err := os.Chmod(binary, 0o755); err != nil {
-		return err
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, binary)
+	cmd.Dir = dir
+	cmd.Env = append(cmd.Environ(),
+		gameenv.Gameserver+"="+creds.Gameserver,
+		gameenv.PlayCookie+"="+creds.Cookie,
+		gameenv.Account+"="+account,
+	)
+	cmd.Stdout = logWriter
+	cmd.Stderr = logWriter
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+	return cmd, nil
+}
+
+type ProcessOptions struct {
+	trackerHooks   []func(account string, tracker *StatusTracker)
+	lifecycleHooks []func(ProcessEvent)
+	mapRenderer    MapRenderer
+}
+
+type ProcessOption func(*ProcessOptions)
+
+func (o *ProcessOptions) apply(opts ...ProcessOption) {
+	for _, op := range opts {
+		op(o)
+	}
+}
+
+// WithTrackerHook calls fn with the game's StatusTracker before it starts
+// reading the logs, so that fn can subscribe to it without missing events.
+// fn must not block.
+func WithTrackerHook(fn func(account string, tracker *StatusTracker)) ProcessOption {
+	return func(o *ProcessOptions) {
+		o.trackerHooks = append(o.trackerHooks, fn)
+	}
+}
+
+// WithMapRenderer sets how mint maps are shown. Maps are not shown unless a
+// renderer is set. A nil renderer is ignored.
+func WithMapRenderer(r MapRenderer) ProcessOption {
+	return func(o *ProcessOptions) {
+		if r != nil {
+			o.mapRenderer = r
+		}
+	}
+}
+
+// WithLifecycleHook calls fn when the game process starts and exits. fn must
+// not block.
+func WithLifecycleHook(fn func(ProcessEvent)) ProcessOption {
+	return func(o *ProcessOptions) {
+		o.lifecycleHooks = append(o.lifecycleHooks, fn)
+	}
+}
+
+func (o *ProcessOptions) runTrackerHooks(account string, tracker *StatusTracker) {
+	for _, fn := range o.trackerHooks {
+		fn(account, tracker)
+	}
+}
+
+func (o *ProcessOptions) runLifecycleHooks(ev ProcessEvent) {
+	for _, fn := range o.lifecycleHooks {
+		fn(ev)
+	}
+}
+
+type ProcessState string
+
+const (
+	ProcessStarted ProcessState = "started"
+	ProcessExited  ProcessState = "exited"
+	// The process exited with an error, without being asked to stop.
+	ProcessCrashed ProcessState = "crashed"
+)
+
+type ProcessEvent struct {
+	Account string
+	State   ProcessState
+	Pid     int
+	LogFile string
+	// For exited and crashed processes, the exit code, or -1 if the process
+	// was killed by a signal.
+	ExitCode int
+	Err      error
+}
+
+func startedEvent(account string, cmd *exec.Cmd, logFile string) ProcessEvent {
+	return ProcessEvent{
+		Account: account,
+		State:   ProcessStarted,
+		Pid:     cmd.Process.Pid,
+		LogFile: logFile,
 	}
+}
+
+// exitedEvent returns the event for a process that exited with the given
+// error. stopped is whether the process was asked to stop.
+func exitedEvent(account string, cmd *exec.Cmd, logFile string, stopped bool, err error) ProcessEvent {
+	ev := ProcessEvent{
+		Account:  account,
+		State:    ProcessExited,
+		Pid:      cmd.Process.Pid,
+		LogFile:  logFile,
+		ExitCode: cmd.ProcessState.ExitCode(),
+		Err:      err,
+	}
+	if err != nil && !stopped {
+		ev.State = ProcessCrashed
+	}
+	return ev
+}
+
+// LaunchProcess starts the game and waits for it to exit. The first Ctrl+C is
+// ignored; the game is stopped on the second.
+func LaunchProcess(ctx context.Context, account string, creds *api.LoginSuccessPayload, opts ...ProcessOption) error {
 	ctx, ca := context.WithCancel(ctx)
+	defer ca()
 	// cancel on sigint
 	sigint := make(chan os.Signal, 1)
 	signal.Notify(sigint, syscall.SIGINT)
+	defer signal.Stop(sigint)
 	go func() {
 		// if the user hits ctrl-c twice, cancel the context
 		count := 0
@@ -74,19 +205,15 @@ func LaunchProcess(ctx context.Context, creds *api.LoginSuccessPayload) error {
 					ca()
 				}
 			case <-ctx.Done():
+				return
 			}
 		}
 	}()
-	cmd := exec.CommandContext(ctx, binary)
-	cmd.Dir = dir
-	cmd.Env = append(cmd.Environ(),
-		"TTR_GAMESERVER="+creds.Gameserver,
-		"TTR_PLAYCOOKIE="+creds.Cookie,
-	)
-	cmd.Stdout = logWriter
-	cmd.Stderr = logWriter
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setpgid: true,
+
+	p, err := StartProcess(ctx, account, creds, opts...)
+	if err != nil {
+		return err
 	}
-	return cmd.Run()
+	<-p.Done()
+	return p.Err()
 }
@@ -8,13 +8,14 @@ import (
 	"image"
 	"image/draw"
 	"image/png"
-	"regexp"
 	"runtime"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/go-gl/gl/v4.6-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/kralicky/ttr/pkg/gamelog"
 	"github.com/sirupsen/logrus"
 	log "github.com/sirupsen/logrus"
 )
@@ -25,6 +26,7 @@ var MintMaps embed.FS
 type MintInfo struct {
 	StageId int
 	Floor   int
+	RoomIds []int
 }
 
 func (i MintInfo) MapImage() (*image.RGBA, error) {
@@ -55,14 +57,10 @@ func (i MintInfo) MapImage() (*image.RGBA, error) {
 	return rgba, nil
 }
 
-var infoRegex = regexp.MustCompile(`^:.*: stageId (\d+), floor (\d+), \[[\d\s,]+\]$`)
-
 func ScanForMintInfo(logs <-chan string) (MintInfo, error) {
 	for line := range logs {
-		if matches := infoRegex.FindStringSubmatch(line); matches != nil {
-			stageId, _ := strconv.Atoi(matches[1])
-			floor, _ := strconv.Atoi(matches[2])
-			return MintInfo{StageId: stageId, Floor: floor}, nil
+		if info, ok := gamelog.ParseFloorInfo(line); ok && info.Facility == gamelog.Mint {
+			return MintInfo{StageId: info.StageId, Floor: info.Floor, RoomIds: info.RoomIds}, nil
 		}
 	}
 	return MintInfo{}, errors.New("no mint info found")
@@ -83,6 +81,19 @@ func (m MintInfo) String() string {
 	return fmt.Sprintf("%s Mint, Floor %d", kind, m.Floor+1)
 }
 
+// Description returns a textual description of the floor, for when the map
+// can't be shown.
+func (m MintInfo) Description() string {
+	if len(m.RoomIds) == 0 {
+		return m.String()
+	}
+	ids := make([]string, len(m.RoomIds))
+	for i, id := range m.RoomIds {
+		ids[i] = strconv.Itoa(id)
+	}
+	return fmt.Sprintf("%s: %d rooms (%s)", m, len(m.RoomIds), strings.Join(ids, ", "))
+}
+
 var (
 	glfwInitialized = make(chan struct{})
 	glfwTasks       = make(chan func() error)
@@ -181,16 +192,42 @@ func ShowMintInfo(ctx context.Context, info MintInfo) error {
 	return nil
 }
 
+// RunMintInfoManager shows the map of each mint floor the toon enters in a
+// window.
 func RunMintInfoManager(statusTracker *StatusTracker) {
+	RunMapRenderer(statusTracker, WindowRenderer{})
+}
+
+// RunMapRenderer shows the map of each mint floor the toon enters using the
+// given renderer, until the tracker stops. It should be called before the
+// tracker starts, or zones entered in the meantime may be missed.
+func RunMapRenderer(statusTracker *StatusTracker, renderer MapRenderer) {
+	renderMaps(subscribeMapRenderer(statusTracker), renderer)
+}
+
+// subscribeMapRenderer subscribes to the events needed by renderMaps. It is
+// separate so that the subscription can be made before the tracker starts,
+// and the maps rendered in the background.
+func subscribeMapRenderer(statusTracker *StatusTracker) *Subscription {
+	return statusTracker.Subscribe(SubscribeOptions{Buffer: 2048})
+}
+
+func renderMaps(sub *Subscription, renderer MapRenderer) {
+	defer sub.Close()
+	defer func() {
+		if dropped := sub.Dropped(); dropped > 0 {
+			log.Debugf("mint info manager dropped %d log lines", dropped)
+		}
+	}()
 	var ca context.CancelFunc
-	for status := range statusTracker.C {
+	for status := range sub.Zones() {
 		if ca != nil {
 			ca()
 		}
 		var ctx context.Context
 		ctx, ca = context.WithCancel(context.Background())
-		log.Debugf("new zone: %s", status.Request)
-		if status.Request.Where == "MintInterior" {
+		log.Debugf("new zone: %s %s", status, status.Request)
+		if f, ok := gamelog.FacilityForWhere(status.Request.Where); ok && f == gamelog.Mint {
 			log.Debugf("entered mint, waiting for logs...")
 			go func() {
 				info, err := ScanForMintInfo(status.ZoneLogs)
@@ -199,7 +236,9 @@ func RunMintInfoManager(statusTracker *StatusTracker) {
 					return
 				}
 				log.Debugf("mint info: %s", info)
-				ShowMintInfo(ctx, info)
+				if err := renderer.Render(ctx, info); err != nil {
+					log.Warnf("error showing mint map: %v", err)
+				}
 			}()
 		}
 	}
@@ -0,0 +1,126 @@
+package game
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/kralicky/ttr/pkg/api"
+	"github.com/kralicky/ttr/pkg/gamelog"
+)
+
+// Process is a game process started with StartProcess.
+type Process struct {
+	Account string
+	Started time.Time
+	LogFile string
+
+	cmd    *exec.Cmd
+	done   chan struct{}
+	killed atomic.Bool
+
+	mu   sync.Mutex
+	zone *EnterRequestStatus
+	err  error
+}
+
+// StartProcess starts the game without waiting for it to exit. The game's
+// output is written to a new log file, and the zone the toon is in is tracked
+// from it and can be read with Zone. Facility maps are shown if a renderer is
+// set with WithMapRenderer.
+func StartProcess(ctx context.Context, account string, creds *api.LoginSuccessPayload, opts ...ProcessOption) (*Process, error) {
+	options := ProcessOptions{}
+	options.apply(opts...)
+
+	f, err := createLogFile()
+	if err != nil {
+		return nil, err
+	}
+
+	statusR, statusW := io.Pipe()
+	cmd, err := gameCommand(ctx, account, creds, io.MultiWriter(gamelog.NewTimestampWriter(f), statusW))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	p := &Process{
+		Account: account,
+		Started: time.Now(),
+		LogFile: f.Name(),
+		cmd:     cmd,
+		done:    make(chan struct{}),
+	}
+
+	statusTracker := NewStatusTracker(statusR)
+	// only the latest zone is needed
+	sub := statusTracker.Subscribe(SubscribeOptions{
+		Buffer: 1,
+		Policy: PolicyCoalesce,
+		Filter: ZonesOnly,
+	})
+	options.runTrackerHooks(account, statusTracker)
+	if options.mapRenderer != nil {
+		mapSub := subscribeMapRenderer(statusTracker)
+		go renderMaps(mapSub, options.mapRenderer)
+	}
+	go statusTracker.Run()
+	go func() {
+		for ev := range sub.C {
+			zone := ev.Zone
+			p.mu.Lock()
+			p.zone = &zone
+			p.mu.Unlock()
+		}
+	}()
+	options.runLifecycleHooks(startedEvent(account, cmd, p.LogFile))
+	go func() {
+		err := cmd.Wait()
+		statusW.Close()
+		f.Close()
+		options.runLifecycleHooks(exitedEvent(account, cmd, p.LogFile, ctx.Err() != nil || p.killed.Load(), err))
+		p.mu.Lock()
+		p.err = err
+		p.mu.Unlock()
+		close(p.done)
+	}()
+	return p, nil
+}
+
+func (p *Process) Pid() int {
+	return p.cmd.Process.Pid
+}
+
+// Zone returns the zone the toon most recently entered, or nil if it is not
+// known yet.
+func (p *Process) Zone() *EnterRequestStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.zone
+}
+
+// Done is closed when the process exits.
+func (p *Process) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err returns the error the process exited with, once it has exited.
+func (p *Process) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// Kill asks the game to exit by sending SIGTERM to its process group.
+func (p *Process) Kill() error {
+	p.killed.Store(true)
+	return syscall.Kill(-p.cmd.Process.Pid, syscall.SIGTERM)
+}
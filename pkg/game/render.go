@@ -0,0 +1,173 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/kralicky/ttr/pkg/termimage"
+)
+
+// MapRenderer shows the map of a mint floor. Render is called when the toon
+// enters the floor, and ctx is canceled when it leaves.
+type MapRenderer interface {
+	Render(ctx context.Context, info MintInfo) error
+}
+
+// Map renderer names, as used in the config.
+const (
+	RendererWindow = "window"
+	RendererFile   = "file"
+	RendererOpen   = "open"
+	RendererKitty  = "kitty"
+	RendererITerm  = "iterm"
+	RendererSixel  = "sixel"
+	RendererText   = "text"
+)
+
+var MapRenderers = []string{
+	RendererWindow,
+	RendererFile,
+	RendererOpen,
+	RendererKitty,
+	RendererITerm,
+	RendererSixel,
+	RendererText,
+}
+
+type MapRendererOptions struct {
+	// Path the map is written to by the file and open renderers. Defaults to
+	// mint-map.png in the data directory.
+	Path string
+	// Output of the terminal and text renderers. Defaults to os.Stdout.
+	Out io.Writer
+}
+
+// NewMapRenderer returns the renderer with the given name (one of
+// MapRenderers).
+func NewMapRenderer(name string, opts MapRendererOptions) (MapRenderer, error) {
+	if opts.Path == "" {
+		dir, err := DataDir()
+		if err != nil {
+			return nil, err
+		}
+		opts.Path = filepath.Join(dir, "mint-map.png")
+	}
+	if opts.Out == nil {
+		opts.Out = os.Stdout
+	}
+	switch name {
+	case RendererWindow:
+		return WindowRenderer{}, nil
+	case RendererFile:
+		return FileRenderer{Path: opts.Path}, nil
+	case RendererOpen:
+		return OpenRenderer{Path: opts.Path}, nil
+	case RendererKitty, RendererITerm, RendererSixel:
+		return TerminalRenderer{Out: opts.Out, Protocol: name}, nil
+	case RendererText:
+		return TextRenderer{Out: opts.Out}, nil
+	default:
+		return nil, fmt.Errorf("unknown map renderer %q (expected one of %v)", name, MapRenderers)
+	}
+}
+
+// WindowRenderer shows the map in a window. It requires OpenGL 4.6, and
+// RunGLFW must be running.
+type WindowRenderer struct{}
+
+func (WindowRenderer) Render(ctx context.Context, info MintInfo) error {
+	return ShowMintInfo(ctx, info)
+}
+
+// FileRenderer writes the map to a png file, replacing the previous map. The
+// file is replaced atomically, so it can be watched by an image viewer.
+type FileRenderer struct {
+	Path string
+}
+
+func (r FileRenderer) Render(_ context.Context, info MintInfo) error {
+	img, err := info.MapImage()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.Path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(r.Path), ".mint-map-*.png")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), r.Path)
+}
+
+// OpenRenderer writes the map to a file, and opens it with the default image
+// viewer.
+type OpenRenderer struct {
+	Path string
+}
+
+func (r OpenRenderer) Render(ctx context.Context, info MintInfo) error {
+	if err := (FileRenderer{Path: r.Path}).Render(ctx, info); err != nil {
+		return err
+	}
+	opener := "xdg-open"
+	if runtime.GOOS == "darwin" {
+		opener = "open"
+	}
+	// not tied to ctx; the viewer should stay open after the toon leaves
+	cmd := exec.Command(opener, r.Path)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// TerminalRenderer prints the map to a terminal that supports inline
+// images, using the kitty, iterm or sixel protocol.
+type TerminalRenderer struct {
+	Out      io.Writer
+	Protocol string
+}
+
+func (r TerminalRenderer) Render(_ context.Context, info MintInfo) error {
+	img, err := info.MapImage()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(r.Out, info.Description())
+	switch r.Protocol {
+	case RendererKitty:
+		return termimage.WriteKitty(r.Out, img)
+	case RendererITerm:
+		return termimage.WriteITerm(r.Out, img, "mint-map.png")
+	case RendererSixel:
+		return termimage.WriteSixel(r.Out, img)
+	default:
+		return fmt.Errorf("unknown terminal graphics protocol %q", r.Protocol)
+	}
+}
+
+// TextRenderer prints a description of the floor instead of the map.
+type TextRenderer struct {
+	Out io.Writer
+}
+
+func (r TextRenderer) Render(_ context.Context, info MintInfo) error {
+	_, err := fmt.Fprintln(r.Out, info.Description())
+	return err
+}
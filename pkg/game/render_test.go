@@ -0,0 +1,49 @@
+package game_test
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kralicky/ttr/pkg/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRenderer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "maps", "mint-map.png")
+	r, err := game.NewMapRenderer(game.RendererFile, game.MapRendererOptions{Path: path})
+	require.NoError(t, err)
+
+	info := game.MintInfo{StageId: game.BullionMintId, Floor: 4}
+	require.NoError(t, r.Render(context.Background(), info))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	img, err := png.Decode(f)
+	require.NoError(t, err)
+	expected, err := info.MapImage()
+	require.NoError(t, err)
+	assert.Equal(t, expected.Bounds(), img.Bounds())
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestTextRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	r, err := game.NewMapRenderer(game.RendererText, game.MapRendererOptions{Out: &buf})
+	require.NoError(t, err)
+
+	info := game.MintInfo{StageId: game.CoinMintId, Floor: 0, RoomIds: []int{0, 18, 4, 25}}
+	require.NoError(t, r.Render(context.Background(), info))
+	assert.Equal(t, "Coin Mint, Floor 1: 4 rooms (0, 18, 4, 25)\n", buf.String())
+
+	_, err = game.NewMapRenderer("bogus", game.MapRendererOptions{Out: &buf})
+	assert.Error(t, err)
+}
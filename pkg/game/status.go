@@ -2,91 +2,335 @@ package game
 
 import (
 	"bufio"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
-	"strings"
+	"sync"
+
+	"github.com/kralicky/ttr/pkg/gamelog"
+	"github.com/kralicky/ttr/pkg/zones"
 )
 
+// StatusTracker reads the game log, and publishes zone changes and log lines
+// to any number of subscribers. See Subscribe.
 type StatusTracker struct {
 	logReader io.Reader
-	C         chan *ActiveZone
+	// Errors receives an error for each zone enter line that could not be
+	// parsed. Errors are dropped if the channel is not being read.
+	Errors chan error
+
+	mu      sync.Mutex
+	subs    map[*Subscription]struct{}
+	current *EnterRequestStatus
+	done    bool
 }
 
-type ActiveZone struct {
-	Request EnterRequestStatus
+// ParseError is sent on StatusTracker.Errors when a line could not be parsed.
+type ParseError struct {
+	Line string
+	Err  error
+}
 
-	ZoneLogs chan string // buffered channel of lines
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("error parsing log line %q: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
 }
 
 func NewStatusTracker(logStream io.Reader) *StatusTracker {
 	return &StatusTracker{
 		logReader: logStream,
-		C:         make(chan *ActiveZone, 1),
+		Errors:    make(chan error, 16),
+		subs:      map[*Subscription]struct{}{},
 	}
 }
 
-const enterPrefix = "enter(requestStatus="
-
 const (
 	CoinMintId    = 12500
 	DollarMintId  = 12600
 	BullionMintId = 12700
 )
 
-type EnterRequestStatus struct {
-	Loader  string `json:"loader"`
-	Where   string `json:"where"`
-	How     string `json:"how"`
-	ZoneId  *int64 `json:"zoneId,omitempty"`
-	HoodId  *int64 `json:"hoodId,omitempty"`
-	ShardId *int64 `json:"shardId,omitempty"`
-	AvId    *int64 `json:"avId,omitempty"`
-	MintId  *int64 `json:"mintId,omitempty"`
-}
-
-func (e EnterRequestStatus) String() string {
-	bytes, _ := json.Marshal(e)
-	return string(bytes)
-}
-
-func toRequestStatusJson(str string) string {
-	return strings.ReplaceAll(strings.ReplaceAll(str, `'`, `"`), `: None`, `: null`)
-}
+type EnterRequestStatus = gamelog.EnterRequestStatus
 
 func (r *StatusTracker) Run() {
-	defer close(r.C)
+	defer r.closeAll()
+	defer close(r.Errors)
 	scan := bufio.NewScanner(r.logReader)
-	var curZoneLogs chan string
 	for scan.Scan() {
 		line := scan.Text()
-		idx := strings.Index(line, enterPrefix)
-		if idx != -1 {
-			// new zone
-			if curZoneLogs != nil {
-				close(curZoneLogs)
+		status, err := gamelog.ParseEnterRequestStatus(line)
+		switch {
+		case err == nil:
+			r.publish(TrackerEvent{Kind: ZoneEvent, Zone: status})
+		case errors.Is(err, gamelog.ErrNotEnterRequest):
+			r.publish(TrackerEvent{Kind: LogEvent, Line: line})
+		default:
+			select {
+			case r.Errors <- &ParseError{Line: line, Err: err}:
+			default:
 			}
-			statusJson := toRequestStatusJson(line[idx+len(enterPrefix) : len(line)-1])
-			var status EnterRequestStatus
-			if err := json.Unmarshal([]byte(statusJson), &status); err != nil {
-				continue
+		}
+	}
+}
+
+func (r *StatusTracker) publish(ev TrackerEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if ev.Kind == ZoneEvent {
+		zone := ev.Zone
+		r.current = &zone
+	} else if r.current != nil {
+		ev.Zone = *r.current
+	}
+	for sub := range r.subs {
+		sub.send(ev)
+	}
+}
+
+func (r *StatusTracker) closeAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.done = true
+	for sub := range r.subs {
+		delete(r.subs, sub)
+		close(sub.ch)
+	}
+}
+
+type EventKind int
+
+const (
+	// The toon entered a new zone.
+	ZoneEvent EventKind = iota
+	// A line was logged.
+	LogEvent
+)
+
+type TrackerEvent struct {
+	Kind EventKind
+	// For zone events, the zone that was entered. For log events, the zone
+	// the line was logged in, if known.
+	Zone EnterRequestStatus
+	// For log events, the line that was logged.
+	Line string
+}
+
+// Policy decides what happens when an event is published to a subscriber
+// whose buffer is full.
+type Policy int
+
+const (
+	// Drop the new event. This is the default.
+	PolicyDrop Policy = iota
+	// Wait until the subscriber has room. This blocks the tracker and every
+	// other subscriber, and eventually the game's log output, so it should
+	// only be used by subscribers that read promptly.
+	PolicyBlock
+	// Drop the oldest buffered event to make room for the new one, so that
+	// the subscriber always sees the latest events.
+	PolicyCoalesce
+)
+
+type SubscribeOptions struct {
+	// Number of events buffered for the subscriber. Defaults to 64.
+	Buffer int
+	Policy Policy
+	// If set, only events for which Filter returns true are published to the
+	// subscriber. Filtered events are not counted as dropped.
+	Filter func(TrackerEvent) bool
+}
+
+// ZonesOnly is a filter that only accepts zone events.
+func ZonesOnly(ev TrackerEvent) bool {
+	return ev.Kind == ZoneEvent
+}
+
+type Subscription struct {
+	// C receives the subscribed events. It is closed when the subscription
+	// is closed or the tracker stops.
+	C <-chan TrackerEvent
+
+	tracker *StatusTracker
+	ch      chan TrackerEvent
+	opts    SubscribeOptions
+	closed  chan struct{}
+	once    sync.Once
+
+	dropMu  sync.Mutex
+	dropped uint64
+}
+
+// Subscribe returns a new subscription to the tracker's events. If the
+// toon is already in a zone, a zone event for it is sent first.
+func (r *StatusTracker) Subscribe(opts SubscribeOptions) *Subscription {
+	if opts.Buffer <= 0 {
+		opts.Buffer = 64
+	}
+	ch := make(chan TrackerEvent, opts.Buffer)
+	sub := &Subscription{
+		C:       ch,
+		tracker: r,
+		ch:      ch,
+		opts:    opts,
+		closed:  make(chan struct{}),
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.done {
+		close(ch)
+		return sub
+	}
+	r.subs[sub] = struct{}{}
+	if r.current != nil {
+		sub.send(TrackerEvent{Kind: ZoneEvent, Zone: *r.current})
+	}
+	return sub
+}
+
+// send publishes an event to the subscriber according to its policy. The
+// tracker's lock must be held.
+func (s *Subscription) send(ev TrackerEvent) {
+	if s.opts.Filter != nil && !s.opts.Filter(ev) {
+		return
+	}
+	switch s.opts.Policy {
+	case PolicyBlock:
+		select {
+		case s.ch <- ev:
+		case <-s.closed:
+		}
+	case PolicyCoalesce:
+		for {
+			select {
+			case s.ch <- ev:
+				return
+			default:
 			}
-			curZoneLogs = make(chan string, 2048)
-			az := &ActiveZone{
-				Request:  status,
-				ZoneLogs: curZoneLogs,
+			select {
+			case <-s.ch:
+				s.addDropped(1)
+			default:
 			}
-			r.C <- az
-		} else {
+		}
+	default:
+		select {
+		case s.ch <- ev:
+		default:
+			s.addDropped(1)
+		}
+	}
+}
+
+func (s *Subscription) addDropped(n uint64) {
+	s.dropMu.Lock()
+	s.dropped += n
+	s.dropMu.Unlock()
+}
+
+// Dropped returns the number of events that were not delivered to the
+// subscriber because its buffer was full.
+func (s *Subscription) Dropped() uint64 {
+	s.dropMu.Lock()
+	defer s.dropMu.Unlock()
+	return s.dropped
+}
+
+// Close stops the subscription and closes C.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		// unblock the tracker if it is waiting to send to this subscriber
+		close(s.closed)
+		s.tracker.mu.Lock()
+		defer s.tracker.mu.Unlock()
+		if _, ok := s.tracker.subs[s]; ok {
+			delete(s.tracker.subs, s)
+			close(s.ch)
+		}
+	})
+}
+
+type ActiveZone struct {
+	Request EnterRequestStatus
+
+	ZoneLogs chan string // buffered channel of lines
+}
+
+// String returns the name of the zone, e.g. "Loopy Lane" or "Bullion Mint".
+func (a *ActiveZone) String() string {
+	return LocationName(a.Request)
+}
+
+// LocationName returns a human readable name for the location in the request
+// status. If the location is not in the zone database, the request's Where
+// field is returned.
+func LocationName(req EnterRequestStatus) string {
+	// interiors such as mints are given a dynamic zone ID, but the
+	// request identifies the facility
+	if req.MintId != nil {
+		if z, ok := zones.Lookup(*req.MintId); ok {
+			return z.Name
+		}
+	}
+	// only trust the zone ID if it belongs to the hood (the estate, for
+	// example, also has a dynamic zone ID)
+	if req.ZoneId != nil && (req.HoodId == nil || zones.HoodId(*req.ZoneId) == *req.HoodId) {
+		if z, ok := zones.Lookup(*req.ZoneId); ok {
+			return z.Name
+		}
+	}
+	if req.HoodId != nil {
+		if name := zones.HoodName(*req.HoodId); name != "" {
+			return name
+		}
+	}
+	return req.Where
+}
+
+// Zones groups the subscription's events by zone. An ActiveZone is sent for
+// each zone event, and the log lines that follow are sent on its ZoneLogs
+// channel, which is closed when the next zone is entered. Lines that don't
+// fit in the ZoneLogs buffer are counted as dropped. Callers that stop reading
+// before the tracker stops must Close the subscription, which closes the
+// returned channel.
+func (s *Subscription) Zones() <-chan *ActiveZone {
+	zones := make(chan *ActiveZone, 1)
+	go func() {
+		defer close(zones)
+		var curZoneLogs chan string
+		defer func() {
 			if curZoneLogs != nil {
+				close(curZoneLogs)
+			}
+		}()
+		for ev := range s.C {
+			switch ev.Kind {
+			case ZoneEvent:
+				if curZoneLogs != nil {
+					close(curZoneLogs)
+				}
+				curZoneLogs = make(chan string, s.opts.Buffer)
 				select {
-				case curZoneLogs <- line:
+				case zones <- &ActiveZone{
+					Request:  ev.Zone,
+					ZoneLogs: curZoneLogs,
+				}:
+				case <-s.closed:
+					return
+				}
+			case LogEvent:
+				if curZoneLogs == nil {
+					continue
+				}
+				select {
+				case curZoneLogs <- ev.Line:
 				default:
-					// buffer is full - it's not being read, so ignore
+					s.addDropped(1)
 				}
 			}
 		}
-	}
-	if curZoneLogs != nil {
-		close(curZoneLogs)
-	}
+	}()
+	return zones
 }
@@ -3,9 +3,11 @@ package game_test
 import (
 	"io"
 	"testing"
+	"time"
 
 	"github.com/kralicky/ttr/pkg/game"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStatusTracker(t *testing.T) {
@@ -14,7 +16,7 @@ func TestStatusTracker(t *testing.T) {
 
 	r, w := io.Pipe()
 	tracker := game.NewStatusTracker(r)
-	c := tracker.C
+	c := tracker.Subscribe(game.SubscribeOptions{Policy: game.PolicyBlock}).Zones()
 	go tracker.Run()
 
 	w.Write([]byte(zone1))
@@ -28,6 +30,7 @@ func TestStatusTracker(t *testing.T) {
 	assert.Equal(t, *az.Request.ZoneId, int64(12345))
 	assert.Nil(t, az.Request.ShardId)
 	assert.Equal(t, *az.Request.AvId, int64(-1))
+	assert.Equal(t, map[string]any{"ownerId": int64(12345)}, az.Request.Extra)
 
 	w.Write([]byte("sample log 1\n"))
 	w.Write([]byte("sample log 2\n"))
@@ -79,3 +82,173 @@ func TestStatusTracker(t *testing.T) {
 		t.Errorf("expected channel to be closed")
 	}
 }
+
+func TestStatusTrackerErrors(t *testing.T) {
+	r, w := io.Pipe()
+	tracker := game.NewStatusTracker(r)
+	sub := tracker.Subscribe(game.SubscribeOptions{Filter: game.ZonesOnly})
+	go tracker.Run()
+
+	w.Write([]byte(":vlt: enter(requestStatus={'loader': 'SafeZoneLoader', 'where': \n"))
+	err := <-tracker.Errors
+	var parseErr *game.ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Contains(t, parseErr.Line, "enter(requestStatus=")
+
+	w.Write([]byte(":vlt: enter(requestStatus={'loader': 'SafeZoneLoader', 'where': 'Playground', 'how': 'TeleportIn', 'flag': True, 'pos': (1, 2)})\n"))
+	ev := <-sub.C
+	assert.Equal(t, "Playground", ev.Zone.Where)
+	assert.Equal(t, true, ev.Zone.Extra["flag"])
+
+	w.Close()
+	_, ok := <-tracker.Errors
+	assert.False(t, ok)
+}
+
+const testZone = `:vlt: enter(requestStatus={'loader': 'SafeZoneLoader', 'where': 'Playground', 'how': 'TeleportIn', 'hoodId': 2000, 'zoneId': 2000})`
+
+// runTracker writes the lines to a tracker and waits for it to stop.
+func runTracker(tracker *game.StatusTracker, w io.WriteCloser, lines ...string) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tracker.Run()
+	}()
+	for _, line := range lines {
+		w.Write([]byte(line + "\n"))
+	}
+	w.Close()
+	<-done
+}
+
+func drain(c <-chan game.TrackerEvent) []game.TrackerEvent {
+	var events []game.TrackerEvent
+	for ev := range c {
+		events = append(events, ev)
+	}
+	return events
+}
+
+func TestSubscriptionPolicies(t *testing.T) {
+	r, w := io.Pipe()
+	tracker := game.NewStatusTracker(r)
+	drop := tracker.Subscribe(game.SubscribeOptions{Buffer: 2, Policy: game.PolicyDrop})
+	coalesce := tracker.Subscribe(game.SubscribeOptions{Buffer: 2, Policy: game.PolicyCoalesce})
+	zones := tracker.Subscribe(game.SubscribeOptions{Buffer: 2, Filter: game.ZonesOnly})
+
+	runTracker(tracker, w, testZone, "line 1", "line 2", "line 3")
+
+	events := drain(drop.C)
+	require.Len(t, events, 2)
+	assert.Equal(t, game.ZoneEvent, events[0].Kind)
+	assert.Equal(t, "line 1", events[1].Line)
+	assert.Equal(t, "Playground", events[1].Zone.Where)
+	assert.EqualValues(t, 2, drop.Dropped())
+
+	events = drain(coalesce.C)
+	require.Len(t, events, 2)
+	assert.Equal(t, "line 2", events[0].Line)
+	assert.Equal(t, "line 3", events[1].Line)
+	assert.EqualValues(t, 2, coalesce.Dropped())
+
+	events = drain(zones.C)
+	require.Len(t, events, 1)
+	assert.Equal(t, game.ZoneEvent, events[0].Kind)
+	assert.EqualValues(t, 0, zones.Dropped())
+}
+
+func TestSubscriptionBlock(t *testing.T) {
+	r, w := io.Pipe()
+	tracker := game.NewStatusTracker(r)
+	sub := tracker.Subscribe(game.SubscribeOptions{Buffer: 1, Policy: game.PolicyBlock})
+	received := make(chan []game.TrackerEvent)
+	go func() {
+		received <- drain(sub.C)
+	}()
+
+	runTracker(tracker, w, testZone, "line 1", "line 2", "line 3")
+
+	events := <-received
+	require.Len(t, events, 4)
+	assert.Equal(t, "line 3", events[3].Line)
+	assert.EqualValues(t, 0, sub.Dropped())
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	r, w := io.Pipe()
+	tracker := game.NewStatusTracker(r)
+	// a blocking subscriber that is never read must not stall the tracker
+	// once it is closed
+	blocked := tracker.Subscribe(game.SubscribeOptions{Buffer: 1, Policy: game.PolicyBlock})
+	other := tracker.Subscribe(game.SubscribeOptions{Policy: game.PolicyBlock})
+	go tracker.Run()
+
+	w.Write([]byte(testZone + "\n"))
+	<-other.C
+	go w.Write([]byte("line 1\n"))
+	blocked.Close()
+	ev := <-other.C
+	assert.Equal(t, "line 1", ev.Line)
+	w.Close()
+
+	_, ok := <-other.C
+	assert.False(t, ok)
+
+	// subscribing after the tracker stopped returns a closed subscription
+	late := tracker.Subscribe(game.SubscribeOptions{})
+	_, ok = <-late.C
+	assert.False(t, ok)
+}
+
+func TestZonesClose(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	tracker := game.NewStatusTracker(r)
+	sub := tracker.Subscribe(game.SubscribeOptions{Policy: game.PolicyBlock})
+	zones := sub.Zones()
+	go tracker.Run()
+
+	// fill the zones buffer, so that the next zone can't be forwarded until
+	// the subscriber reads
+	for range 3 {
+		w.Write([]byte(testZone + "\n"))
+	}
+	require.Eventually(t, func() bool { return len(zones) == 1 }, time.Second, time.Millisecond)
+
+	// the subscriber stops reading; closing the subscription must stop the
+	// forwarding goroutine without sending the remaining zones
+	sub.Close()
+	var received int
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-zones:
+			if !ok {
+				assert.Equal(t, 1, received)
+				return
+			}
+			received++
+		case <-timeout:
+			t.Fatal("zones channel was not closed")
+		}
+	}
+}
+
+func TestLocationName(t *testing.T) {
+	id := func(i int64) *int64 { return &i }
+	cases := []struct {
+		req  game.EnterRequestStatus
+		want string
+	}{
+		{game.EnterRequestStatus{Where: "Playground", ZoneId: id(2000), HoodId: id(2000)}, "Toontown Central"},
+		{game.EnterRequestStatus{Where: "Street", ZoneId: id(2213), HoodId: id(2000)}, "Loopy Lane"},
+		{game.EnterRequestStatus{Where: "MintInterior", ZoneId: id(23456), HoodId: id(12000), MintId: id(12700)}, "Bullion Mint"},
+		{game.EnterRequestStatus{Where: "Estate", ZoneId: id(12345), HoodId: id(16000)}, "Estate"},
+		{game.EnterRequestStatus{Where: "Unknown", ZoneId: id(70000)}, "Unknown"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, game.LocationName(c.req))
+		az := &game.ActiveZone{Request: c.req}
+		assert.Equal(t, c.want, az.String())
+	}
+}
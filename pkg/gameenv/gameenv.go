@@ -0,0 +1,12 @@
+// Package gameenv defines the environment variables of game processes
+// started by this tool.
+package gameenv
+
+const (
+	// Gameserver and PlayCookie are read by the engine to log in.
+	Gameserver = "TTR_GAMESERVER"
+	PlayCookie = "TTR_PLAYCOOKIE"
+	// Account is set to the name of the account, so that running engines can
+	// be matched to accounts.
+	Account = "TTR_CLI_ACCOUNT"
+)
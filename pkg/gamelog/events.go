@@ -0,0 +1,116 @@
+package gamelog
+
+import "strings"
+
+type Kind string
+
+const (
+	KindZoneEnter       Kind = "zoneEnter"
+	KindFloorInfo       Kind = "floorInfo"
+	KindBossBattleStart Kind = "bossBattleStart"
+	KindDistrictChange  Kind = "districtChange"
+	KindDisconnect      Kind = "disconnect"
+	KindError           Kind = "error"
+	KindTraceback       Kind = "traceback"
+	KindParseError      Kind = "parseError"
+)
+
+// Event is a typed event parsed from one or more lines of the game log.
+type Event interface {
+	Kind() Kind
+}
+
+// ZoneEnter is logged each time the toon enters a new zone.
+type ZoneEnter struct {
+	Status EnterRequestStatus `json:"status"`
+}
+
+func (ZoneEnter) Kind() Kind { return KindZoneEnter }
+
+type Facility string
+
+const (
+	Factory     Facility = "factory"
+	Mint        Facility = "mint"
+	Office      Facility = "office"
+	CountryClub Facility = "countryClub"
+)
+
+// facilityInteriors maps the "where" of the request status inside each kind
+// of facility (lowercased) to the facility.
+var facilityInteriors = map[string]Facility{
+	"factoryinterior":     Factory,
+	"mintinterior":        Mint,
+	"stageinterior":       Office,
+	"countryclubinterior": CountryClub,
+}
+
+// FacilityForWhere returns the kind of facility the toon is in, given the
+// "where" of its request status.
+func FacilityForWhere(where string) (Facility, bool) {
+	f, ok := facilityInteriors[strings.ToLower(where)]
+	return f, ok
+}
+
+// BossBattleWhere is the "where" of the request status when entering a cog
+// HQ boss battle.
+const BossBattleWhere = "cogHQBossBattle"
+
+// FloorInfo is logged when entering a floor of a cog facility, and describes
+// the layout of the floor.
+type FloorInfo struct {
+	Facility Facility `json:"facility,omitempty"`
+	StageId  int      `json:"stageId"`
+	// 0-based floor number
+	Floor   int   `json:"floor"`
+	RoomIds []int `json:"roomIds"`
+}
+
+func (FloorInfo) Kind() Kind { return KindFloorInfo }
+
+// BossBattleStart is logged when entering a cog HQ boss battle.
+type BossBattleStart struct {
+	HoodId int64 `json:"hoodId"`
+	ZoneId int64 `json:"zoneId"`
+}
+
+func (BossBattleStart) Kind() Kind { return KindBossBattleStart }
+
+// DistrictChange is logged when the toon moves to another district.
+type DistrictChange struct {
+	ShardId int64 `json:"shardId"`
+}
+
+func (DistrictChange) Kind() Kind { return KindDistrictChange }
+
+// Disconnect is logged when the connection to the game server is lost.
+type Disconnect struct {
+	Message string `json:"message"`
+}
+
+func (Disconnect) Kind() Kind { return KindDisconnect }
+
+// Error is a message logged by the engine at the error level.
+type Error struct {
+	Category string `json:"category"`
+	Message  string `json:"message"`
+}
+
+func (Error) Kind() Kind { return KindError }
+
+// Traceback is a Python traceback, which spans several lines.
+type Traceback struct {
+	// The final line of the traceback, e.g. "KeyError: 12500".
+	Exception string   `json:"exception"`
+	Lines     []string `json:"lines"`
+}
+
+func (Traceback) Kind() Kind { return KindTraceback }
+
+// ParseError is produced when a matcher recognizes a line but can't parse it.
+type ParseError struct {
+	Matcher string `json:"matcher"`
+	Error   string `json:"error"`
+}
+
+func (ParseError) Kind() Kind { return KindParseError }
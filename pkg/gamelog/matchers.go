@@ -0,0 +1,165 @@
+package gamelog
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Matcher recognizes a kind of log line. Match returns the events for a line,
+// or nil if the line is not recognized.
+type Matcher struct {
+	Name  string
+	Match func(line string) []Event
+}
+
+// Registry is an ordered set of matchers. Every matcher is tried for each
+// line, so a line can produce events from more than one matcher.
+type Registry struct {
+	matchers []Matcher
+}
+
+func NewRegistry(matchers ...Matcher) *Registry {
+	return &Registry{matchers: matchers}
+}
+
+// DefaultRegistry returns a new registry containing the built-in matchers.
+func DefaultRegistry() *Registry {
+	return NewRegistry(
+		NewZoneEnterMatcher(),
+		FloorInfoMatcher,
+		DisconnectMatcher,
+		ErrorMatcher,
+	)
+}
+
+// Register adds a matcher, replacing any existing matcher with the same name.
+func (r *Registry) Register(m Matcher) {
+	for i, existing := range r.matchers {
+		if existing.Name == m.Name {
+			r.matchers[i] = m
+			return
+		}
+	}
+	r.matchers = append(r.matchers, m)
+}
+
+func (r *Registry) Match(line string) []Event {
+	var events []Event
+	for _, m := range r.matchers {
+		events = append(events, m.Match(line)...)
+	}
+	return events
+}
+
+// NewZoneEnterMatcher returns a matcher for "enter(requestStatus={...})"
+// lines. Along with the ZoneEnter event, it produces a BossBattleStart event
+// when entering a boss battle, and a DistrictChange event when the request's
+// shard ID differs from the last one seen. If the request status can't be
+// parsed, a ParseError event is produced instead. The matcher remembers the
+// last shard, so each log needs its own.
+func NewZoneEnterMatcher() Matcher {
+	var lastShard *int64
+	return Matcher{
+		Name: "zoneEnter",
+		Match: func(line string) []Event {
+			status, err := ParseEnterRequestStatus(line)
+			if errors.Is(err, ErrNotEnterRequest) {
+				return nil
+			} else if err != nil {
+				return []Event{ParseError{Matcher: "zoneEnter", Error: err.Error()}}
+			}
+			events := []Event{ZoneEnter{Status: status}}
+			if status.Where == BossBattleWhere {
+				var bb BossBattleStart
+				if status.HoodId != nil {
+					bb.HoodId = *status.HoodId
+				}
+				if status.ZoneId != nil {
+					bb.ZoneId = *status.ZoneId
+				}
+				events = append(events, bb)
+			}
+			if status.ShardId != nil && (lastShard == nil || *lastShard != *status.ShardId) {
+				shard := *status.ShardId
+				lastShard = &shard
+				events = append(events, DistrictChange{ShardId: shard})
+			}
+			return events
+		},
+	}
+}
+
+var floorInfoRegex = regexp.MustCompile(`^:.*: stageId (\d+), floor (\d+), \[([\d\s,]*)\]$`)
+
+// ParseFloorInfo parses a "stageId <id>, floor <n>, [<room ids>]" line.
+func ParseFloorInfo(line string) (FloorInfo, bool) {
+	matches := floorInfoRegex.FindStringSubmatch(strings.TrimRight(line, "\r"))
+	if matches == nil {
+		return FloorInfo{}, false
+	}
+	info := FloorInfo{RoomIds: []int{}}
+	info.StageId, _ = strconv.Atoi(matches[1])
+	info.Floor, _ = strconv.Atoi(matches[2])
+	for _, id := range strings.Split(matches[3], ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(id)); err == nil {
+			info.RoomIds = append(info.RoomIds, n)
+		}
+	}
+	// facilities are numbered within their cog HQ's zone range
+	switch info.StageId - info.StageId%1000 {
+	case 10000:
+		info.Facility = CountryClub
+	case 11000:
+		info.Facility = Factory
+	case 12000:
+		info.Facility = Mint
+	case 13000:
+		info.Facility = Office
+	}
+	return info, true
+}
+
+var FloorInfoMatcher = Matcher{
+	Name: "floorInfo",
+	Match: func(line string) []Event {
+		if info, ok := ParseFloorInfo(line); ok {
+			return []Event{info}
+		}
+		return nil
+	},
+}
+
+var disconnectRegex = regexp.MustCompile(`(?i)\b(lost connection|disconnected)\b`)
+
+var DisconnectMatcher = Matcher{
+	Name: "disconnect",
+	Match: func(line string) []Event {
+		if !disconnectRegex.MatchString(line) {
+			return nil
+		}
+		return []Event{Disconnect{Message: stripCategory(line)}}
+	},
+}
+
+// matches lines logged at the error level, e.g. ":display(error): message"
+var errorRegex = regexp.MustCompile(`^:([\w:.]+)\(error\):\s*(.*)$`)
+
+var ErrorMatcher = Matcher{
+	Name: "error",
+	Match: func(line string) []Event {
+		matches := errorRegex.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if matches == nil {
+			return nil
+		}
+		return []Event{Error{Category: matches[1], Message: matches[2]}}
+	},
+}
+
+var categoryRegex = regexp.MustCompile(`^:[\w:.]+(\(\w+\))?:\s*`)
+
+// stripCategory removes the ":category:" prefix from a log line.
+func stripCategory(line string) string {
+	return categoryRegex.ReplaceAllString(strings.TrimRight(line, "\r"), "")
+}
@@ -0,0 +1,93 @@
+// Package gamelog parses game engine log lines into typed events.
+package gamelog
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+const tracebackStart = "Traceback (most recent call last):"
+
+// Parser parses log lines one at a time. Most events come from a single line
+// and are matched by the parser's registry; Python tracebacks span several
+// lines and are handled by the parser itself.
+type Parser struct {
+	registry  *Registry
+	traceback *Traceback
+}
+
+// NewParser returns a parser using the given registry, or the default
+// registry if nil.
+func NewParser(registry *Registry) *Parser {
+	if registry == nil {
+		registry = DefaultRegistry()
+	}
+	return &Parser{registry: registry}
+}
+
+// Parse parses the next log line, and returns the events it completes.
+func (p *Parser) Parse(line string) []Event {
+	line = strings.TrimRight(line, "\r")
+	if p.traceback != nil {
+		p.traceback.Lines = append(p.traceback.Lines, line)
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			return nil
+		}
+		// the first unindented line is the exception, which ends the traceback
+		tb := *p.traceback
+		tb.Exception = line
+		p.traceback = nil
+		return []Event{tb}
+	}
+	if strings.HasSuffix(line, tracebackStart) {
+		p.traceback = &Traceback{Lines: []string{line}}
+		return nil
+	}
+	return p.registry.Match(line)
+}
+
+// Flush returns any event that is still incomplete, e.g. a traceback that
+// was cut off at the end of the log.
+func (p *Parser) Flush() []Event {
+	if p.traceback == nil {
+		return nil
+	}
+	tb := *p.traceback
+	p.traceback = nil
+	return []Event{tb}
+}
+
+// LineEvent is an event along with the (1-based) line number that completed
+// it.
+type LineEvent struct {
+	Line  int   `json:"line"`
+	Event Event `json:"event"`
+}
+
+func (e LineEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Line  int   `json:"line"`
+		Kind  Kind  `json:"kind"`
+		Event Event `json:"event"`
+	}{e.Line, e.Event.Kind(), e.Event})
+}
+
+// ParseAll parses every line of r.
+func ParseAll(r io.Reader, registry *Registry) ([]LineEvent, error) {
+	p := NewParser(registry)
+	var events []LineEvent
+	scan := bufio.NewScanner(r)
+	n := 0
+	for scan.Scan() {
+		n++
+		for _, e := range p.Parse(scan.Text()) {
+			events = append(events, LineEvent{Line: n, Event: e})
+		}
+	}
+	for _, e := range p.Flush() {
+		events = append(events, LineEvent{Line: n, Event: e})
+	}
+	return events, scan.Err()
+}
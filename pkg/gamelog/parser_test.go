@@ -0,0 +1,74 @@
+package gamelog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kralicky/ttr/pkg/gamelog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// TestGolden parses each testdata/*.log file and compares the events with
+// the corresponding .golden file, which has one JSON event per line. Run with
+// -update to regenerate the golden files.
+func TestGolden(t *testing.T) {
+	logs, err := filepath.Glob(filepath.Join("testdata", "*.log"))
+	require.NoError(t, err)
+	require.NotEmpty(t, logs)
+	for _, path := range logs {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			f, err := os.Open(path)
+			require.NoError(t, err)
+			defer f.Close()
+			events, err := gamelog.ParseAll(f, nil)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			for _, e := range events {
+				require.NoError(t, enc.Encode(e))
+			}
+
+			golden := strings.TrimSuffix(path, ".log") + ".golden"
+			if *update {
+				require.NoError(t, os.WriteFile(golden, buf.Bytes(), 0o644))
+				return
+			}
+			expected, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), buf.String())
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	registry := gamelog.DefaultRegistry()
+	registry.Register(gamelog.Matcher{
+		Name: "disconnect",
+		Match: func(line string) []gamelog.Event {
+			return nil
+		},
+	})
+	p := gamelog.NewParser(registry)
+	assert.Empty(t, p.Parse(":TTRClientRepository: Lost connection to gameserver."))
+	assert.Len(t, p.Parse(":vlt: stageId 12500, floor 0, []"), 1)
+}
+
+func TestFacilityForWhere(t *testing.T) {
+	f, ok := gamelog.FacilityForWhere("mintInterior")
+	assert.True(t, ok)
+	assert.Equal(t, gamelog.Mint, f)
+	f, ok = gamelog.FacilityForWhere("StageInterior")
+	assert.True(t, ok)
+	assert.Equal(t, gamelog.Office, f)
+	_, ok = gamelog.FacilityForWhere("cogHQExterior")
+	assert.False(t, ok)
+}
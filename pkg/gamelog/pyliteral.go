@@ -0,0 +1,237 @@
+package gamelog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParsePyLiteral parses a Python literal, as printed by repr(), into Go
+// values. Dicts become map[string]any (non-string keys are formatted with
+// fmt.Sprint), lists and tuples become []any, ints become int64, floats
+// become float64, True/False become bool, and None becomes nil.
+func ParsePyLiteral(s string) (any, error) {
+	v, rest, err := ParsePyLiteralPrefix(s)
+	if err != nil {
+		return nil, err
+	}
+	if rest = strings.TrimSpace(rest); rest != "" {
+		return nil, fmt.Errorf("unexpected trailing characters: %q", truncate(rest))
+	}
+	return v, nil
+}
+
+// ParsePyLiteralPrefix parses a Python literal at the start of s, and returns
+// the remainder of s.
+func ParsePyLiteralPrefix(s string) (any, string, error) {
+	p := pyParser{s: s}
+	v, err := p.value()
+	if err != nil {
+		return nil, "", err
+	}
+	return v, p.s[p.pos:], nil
+}
+
+type pyParser struct {
+	s   string
+	pos int
+}
+
+func truncate(s string) string {
+	if len(s) > 20 {
+		return s[:20] + "..."
+	}
+	return s
+}
+
+func (p *pyParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *pyParser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) != -1 {
+		p.pos++
+	}
+}
+
+func (p *pyParser) peek() byte {
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *pyParser) value() (any, error) {
+	p.skipSpace()
+	switch c := p.peek(); {
+	case c == 0:
+		return nil, p.errorf("unexpected end of input")
+	case c == '{':
+		return p.dict()
+	case c == '[':
+		return p.sequence('[', ']')
+	case c == '(':
+		return p.sequence('(', ')')
+	case c == '\'' || c == '"':
+		return p.str()
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		return p.number()
+	default:
+		return p.keyword()
+	}
+}
+
+func (p *pyParser) dict() (any, error) {
+	p.pos++ // {
+	m := map[string]any{}
+	for {
+		p.skipSpace()
+		if p.peek() == '}' {
+			p.pos++
+			return m, nil
+		}
+		k, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.peek() != ':' {
+			return nil, p.errorf("expected ':' in dict")
+		}
+		p.pos++
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			key = fmt.Sprint(k)
+		}
+		m[key] = v
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			return nil, p.errorf("expected ',' or '}' in dict")
+		}
+	}
+}
+
+func (p *pyParser) sequence(open, close byte) (any, error) {
+	p.pos++ // open
+	items := []any{}
+	for {
+		p.skipSpace()
+		if p.peek() == close {
+			p.pos++
+			return items, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case close:
+		default:
+			return nil, p.errorf("expected ',' or '%c'", close)
+		}
+	}
+}
+
+func (p *pyParser) str() (any, error) {
+	quote := p.s[p.pos]
+	p.pos++
+	var sb strings.Builder
+	for {
+		if p.pos >= len(p.s) {
+			return nil, p.errorf("unterminated string")
+		}
+		c := p.s[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return sb.String(), nil
+		case c == '\\':
+			if p.pos+1 >= len(p.s) {
+				return nil, p.errorf("unterminated string")
+			}
+			p.pos++
+			if err := p.escape(&sb); err != nil {
+				return nil, err
+			}
+		default:
+			r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+			sb.WriteRune(r)
+			p.pos += size
+		}
+	}
+}
+
+// escape handles the escape sequence after a backslash.
+func (p *pyParser) escape(sb *strings.Builder) error {
+	c := p.s[p.pos]
+	p.pos++
+	switch c {
+	case 'n':
+		sb.WriteByte('\n')
+	case 't':
+		sb.WriteByte('\t')
+	case 'r':
+		sb.WriteByte('\r')
+	case '\\', '\'', '"':
+		sb.WriteByte(c)
+	case 'x', 'u', 'U':
+		n := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+		if p.pos+n > len(p.s) {
+			return p.errorf("invalid \\%c escape", c)
+		}
+		code, err := strconv.ParseUint(p.s[p.pos:p.pos+n], 16, 32)
+		if err != nil {
+			return p.errorf("invalid \\%c escape", c)
+		}
+		sb.WriteRune(rune(code))
+		p.pos += n
+	default:
+		// unknown escapes are kept as-is, as in Python
+		sb.WriteByte('\\')
+		sb.WriteByte(c)
+	}
+	return nil
+}
+
+func (p *pyParser) number() (any, error) {
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte("+-.0123456789eEL", p.s[p.pos]) != -1 {
+		p.pos++
+	}
+	// Python 2 longs are printed with an L suffix
+	lit := strings.TrimSuffix(p.s[start:p.pos], "L")
+	if i, err := strconv.ParseInt(lit, 10, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(lit, 64); err == nil {
+		return f, nil
+	}
+	p.pos = start
+	return nil, p.errorf("invalid number %q", lit)
+}
+
+func (p *pyParser) keyword() (any, error) {
+	for _, kw := range []struct {
+		name  string
+		value any
+	}{{"None", nil}, {"True", true}, {"False", false}} {
+		if strings.HasPrefix(p.s[p.pos:], kw.name) {
+			p.pos += len(kw.name)
+			return kw.value, nil
+		}
+	}
+	return nil, p.errorf("unexpected %q", truncate(p.s[p.pos:]))
+}
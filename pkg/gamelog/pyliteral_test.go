@@ -0,0 +1,76 @@
+package gamelog_test
+
+import (
+	"testing"
+
+	"github.com/kralicky/ttr/pkg/gamelog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePyLiteral(t *testing.T) {
+	cases := map[string]any{
+		`None`:                 nil,
+		`True`:                 true,
+		`False`:                false,
+		`-12`:                  int64(-12),
+		`4000000000L`:          int64(4000000000),
+		`1.5`:                  1.5,
+		`'it\'s'`:              "it's",
+		`"Flippy's Place"`:     "Flippy's Place",
+		`'tab\there \x41'`:     "tab\there A",
+		`(1, 'two', None)`:     []any{int64(1), "two", nil},
+		`()`:                   []any{},
+		`[1, [2, (3,)]]`:       []any{int64(1), []any{int64(2), []any{int64(3)}}},
+		`{}`:                   map[string]any{},
+		`{1: 'a', 'b': True,}`: map[string]any{"1": "a", "b": true},
+		`{'a': {'b': [None]}}`: map[string]any{"a": map[string]any{"b": []any{nil}}},
+	}
+	for input, expected := range cases {
+		v, err := gamelog.ParsePyLiteral(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, v, input)
+	}
+
+	for _, input := range []string{
+		``,
+		`{'a': 1`,
+		`{'a' 1}`,
+		`'unterminated`,
+		`[1 2]`,
+		`1 2`,
+		`Nothing`,
+		`u'prefixed'`, // string prefixes are not supported
+	} {
+		_, err := gamelog.ParsePyLiteral(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestParseEnterRequestStatus(t *testing.T) {
+	status, err := gamelog.ParseEnterRequestStatus(`:vlt: enter(requestStatus={'loader': 'safeZoneLoader', 'where': 'estate', 'how': 'teleportIn', 'hoodId': 16000, 'zoneId': 30001, 'shardId': None, 'avId': 100000001, 'ownerId': 100000002, 'ownerName': "Flippy's Estate", 'fromHood': True, 'pos': (1.5, -2, 0)}) `)
+	require.NoError(t, err)
+	assert.Equal(t, "estate", status.Where)
+	assert.Equal(t, int64(30001), *status.ZoneId)
+	assert.Nil(t, status.ShardId)
+	assert.Equal(t, map[string]any{
+		"ownerId":   int64(100000002),
+		"ownerName": "Flippy's Estate",
+		"fromHood":  true,
+		"pos":       []any{1.5, int64(-2), int64(0)},
+	}, status.Extra)
+
+	_, err = gamelog.ParseEnterRequestStatus(":vlt: sample log")
+	assert.ErrorIs(t, err, gamelog.ErrNotEnterRequest)
+
+	for _, line := range []string{
+		`:vlt: enter(requestStatus={'loader': 'safeZoneLoader'`,
+		`:vlt: enter(requestStatus={'loader': 'safeZoneLoader'}`,
+		`:vlt: enter(requestStatus=['safeZoneLoader'])`,
+		`:vlt: enter(requestStatus={'zoneId': '2000'})`,
+	} {
+		_, err := gamelog.ParseEnterRequestStatus(line)
+		assert.Error(t, err, line)
+		assert.NotErrorIs(t, err, gamelog.ErrNotEnterRequest, line)
+	}
+}
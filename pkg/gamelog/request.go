@@ -0,0 +1,98 @@
+package gamelog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const enterPrefix = "enter(requestStatus="
+
+// ErrNotEnterRequest is returned by ParseEnterRequestStatus for lines that
+// are not "enter(requestStatus={...})" lines.
+var ErrNotEnterRequest = errors.New("not an enter request line")
+
+type EnterRequestStatus struct {
+	Loader  string `json:"loader"`
+	Where   string `json:"where"`
+	How     string `json:"how"`
+	ZoneId  *int64 `json:"zoneId,omitempty"`
+	HoodId  *int64 `json:"hoodId,omitempty"`
+	ShardId *int64 `json:"shardId,omitempty"`
+	AvId    *int64 `json:"avId,omitempty"`
+	MintId  *int64 `json:"mintId,omitempty"`
+
+	// Keys not listed above (e.g. ownerId), as parsed by ParsePyLiteral.
+	Extra map[string]any `json:"extra,omitempty"`
+}
+
+func (e EnterRequestStatus) String() string {
+	bytes, _ := json.Marshal(e)
+	return string(bytes)
+}
+
+// ParseEnterRequestStatus parses the request status from an
+// "enter(requestStatus={...})" log line. If the line is not an enter line,
+// ErrNotEnterRequest is returned.
+func ParseEnterRequestStatus(line string) (EnterRequestStatus, error) {
+	idx := strings.Index(line, enterPrefix)
+	if idx == -1 {
+		return EnterRequestStatus{}, ErrNotEnterRequest
+	}
+	v, rest, err := ParsePyLiteralPrefix(line[idx+len(enterPrefix):])
+	if err != nil {
+		return EnterRequestStatus{}, fmt.Errorf("invalid request status: %w", err)
+	}
+	if rest = strings.TrimSpace(rest); !strings.HasPrefix(rest, ")") {
+		return EnterRequestStatus{}, fmt.Errorf("invalid request status: expected ')' after dict, got %q", truncate(rest))
+	}
+	dict, ok := v.(map[string]any)
+	if !ok {
+		return EnterRequestStatus{}, fmt.Errorf("invalid request status: expected a dict, got %T", v)
+	}
+	return requestStatusFromDict(dict)
+}
+
+func requestStatusFromDict(dict map[string]any) (EnterRequestStatus, error) {
+	var status EnterRequestStatus
+	strs := map[string]*string{
+		"loader": &status.Loader,
+		"where":  &status.Where,
+		"how":    &status.How,
+	}
+	ints := map[string]**int64{
+		"zoneId":  &status.ZoneId,
+		"hoodId":  &status.HoodId,
+		"shardId": &status.ShardId,
+		"avId":    &status.AvId,
+		"mintId":  &status.MintId,
+	}
+	for k, v := range dict {
+		if s, ok := strs[k]; ok {
+			switch v := v.(type) {
+			case string:
+				*s = v
+			case nil:
+			default:
+				return EnterRequestStatus{}, fmt.Errorf("invalid request status: %s: expected a string, got %T", k, v)
+			}
+			continue
+		}
+		if i, ok := ints[k]; ok {
+			switch v := v.(type) {
+			case int64:
+				*i = &v
+			case nil:
+			default:
+				return EnterRequestStatus{}, fmt.Errorf("invalid request status: %s: expected an int, got %T", k, v)
+			}
+			continue
+		}
+		if status.Extra == nil {
+			status.Extra = map[string]any{}
+		}
+		status.Extra[k] = v
+	}
+	return status, nil
+}
@@ -0,0 +1,77 @@
+package gamelog
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+)
+
+// The engine does not timestamp its log lines, so the launcher adds marker
+// lines to the log files it writes, e.g.
+//
+//	:ttr-cli: time 2024-06-10T18:04:05Z
+const timestampPrefix = ":ttr-cli: time "
+
+// TimestampInterval is the minimum time between timestamp markers.
+const TimestampInterval = time.Second
+
+// FormatTimestamp returns a timestamp marker line, without a newline.
+func FormatTimestamp(t time.Time) string {
+	return timestampPrefix + t.UTC().Format(time.RFC3339)
+}
+
+// ParseTimestamp parses a timestamp marker line.
+func ParseTimestamp(line string) (time.Time, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimRight(line, "\r"), timestampPrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, rest)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+type timestampWriter struct {
+	w         io.Writer
+	now       func() time.Time
+	last      time.Time
+	lineStart bool
+}
+
+// NewTimestampWriter returns a writer that adds a timestamp marker line
+// before a log line if at least TimestampInterval has passed since the last
+// marker. Markers are only added before lines starting with ':' (i.e.
+// ":category: message" lines), so that they never interrupt a traceback.
+func NewTimestampWriter(w io.Writer) io.Writer {
+	return &timestampWriter{w: w, now: time.Now, lineStart: true}
+}
+
+func (tw *timestampWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if tw.lineStart && p[0] == ':' {
+			if now := tw.now(); now.Sub(tw.last) >= TimestampInterval {
+				if _, err := io.WriteString(tw.w, FormatTimestamp(now)+"\n"); err != nil {
+					return written, err
+				}
+				tw.last = now
+			}
+		}
+		// write up to and including the next newline
+		n := len(p)
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			n = i + 1
+		}
+		m, err := tw.w.Write(p[:n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+		tw.lineStart = p[n-1] == '\n'
+		p = p[n:]
+	}
+	return written, nil
+}
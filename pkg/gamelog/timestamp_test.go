@@ -0,0 +1,46 @@
+package gamelog_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kralicky/ttr/pkg/gamelog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestampWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := gamelog.NewTimestampWriter(&buf)
+	// lines may be split across writes
+	w.Write([]byte("Traceback (most recent call last):\n  File \"a.py\"\n"))
+	w.Write([]byte(":vlt: enter(requestStatus="))
+	w.Write([]byte("{})\n:vlt: next line\n"))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, "Traceback (most recent call last):", lines[0])
+	assert.Equal(t, `  File "a.py"`, lines[1])
+	ts, ok := gamelog.ParseTimestamp(lines[2])
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now(), ts, 2*time.Second)
+	assert.Equal(t, ":vlt: enter(requestStatus={})", lines[3])
+	// less than TimestampInterval has passed, so no marker is added
+	assert.Equal(t, ":vlt: next line", lines[4])
+}
+
+func TestParseTimestamp(t *testing.T) {
+	ts := time.Date(2024, 6, 10, 18, 4, 5, 0, time.UTC)
+	line := gamelog.FormatTimestamp(ts)
+	assert.Equal(t, ":ttr-cli: time 2024-06-10T18:04:05Z", line)
+	parsed, ok := gamelog.ParseTimestamp(line + "\r")
+	require.True(t, ok)
+	assert.True(t, ts.Equal(parsed))
+
+	_, ok = gamelog.ParseTimestamp(":ttr-cli: time yesterday")
+	assert.False(t, ok)
+	_, ok = gamelog.ParseTimestamp(":vlt: stageId 12700, floor 4, []")
+	assert.False(t, ok)
+}
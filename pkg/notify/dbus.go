@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	notificationsName = "org.freedesktop.Notifications"
+	notificationsPath = "/org/freedesktop/Notifications"
+	appName           = "ttr"
+)
+
+// DBusNotifier sends notifications using the freedesktop notification spec
+// (org.freedesktop.Notifications) over D-Bus.
+type DBusNotifier struct {
+	conn *dbus.Conn
+}
+
+type DBusOptions struct {
+	address string
+}
+
+type DBusOption func(*DBusOptions)
+
+func (o *DBusOptions) apply(opts ...DBusOption) {
+	for _, op := range opts {
+		op(o)
+	}
+}
+
+// WithBusAddress connects to the bus at the given address instead of the
+// session bus.
+func WithBusAddress(address string) DBusOption {
+	return func(o *DBusOptions) {
+		o.address = address
+	}
+}
+
+func NewDBusNotifier(opts ...DBusOption) (*DBusNotifier, error) {
+	options := DBusOptions{}
+	options.apply(opts...)
+
+	var conn *dbus.Conn
+	var err error
+	if options.address == "" {
+		conn, err = dbus.ConnectSessionBus()
+	} else {
+		conn, err = dbus.Connect(options.address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &DBusNotifier{conn: conn}, nil
+}
+
+func (d *DBusNotifier) Notify(ctx context.Context, n Notification) error {
+	obj := d.conn.Object(notificationsName, notificationsPath)
+	hints := map[string]dbus.Variant{
+		"urgency": dbus.MakeVariant(byte(n.Urgency)),
+	}
+	call := obj.CallWithContext(ctx, notificationsName+".Notify", 0,
+		appName,    // app_name
+		uint32(0),  // replaces_id
+		"",         // app_icon
+		n.Summary,  // summary
+		n.Body,     // body
+		[]string{}, // actions
+		hints,      // hints
+		int32(-1),  // expire_timeout (server default)
+	)
+	return call.Err
+}
+
+func (d *DBusNotifier) Close() error {
+	return d.conn.Close()
+}
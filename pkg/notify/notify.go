@@ -0,0 +1,24 @@
+// Package notify sends desktop notifications for game events, such as a toon
+// entering a mint or disconnecting.
+package notify
+
+import "context"
+
+type Urgency byte
+
+// Urgency levels, as defined by the freedesktop notification spec.
+const (
+	UrgencyLow Urgency = iota
+	UrgencyNormal
+	UrgencyCritical
+)
+
+type Notification struct {
+	Summary string
+	Body    string
+	Urgency Urgency
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
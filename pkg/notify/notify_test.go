@@ -0,0 +1,181 @@
+package notify_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/kralicky/ttr/pkg/game"
+	"github.com/kralicky/ttr/pkg/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startBus starts a private dbus-daemon and returns its address.
+func startBus(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("dbus-daemon"); err != nil {
+		t.Skip("dbus-daemon not found")
+	}
+	cmd := exec.Command("dbus-daemon", "--session", "--nofork", "--print-address=1")
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	addr, err := bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err)
+	return addr[:len(addr)-1]
+}
+
+type receivedNotification struct {
+	appName string
+	summary string
+	body    string
+	urgency byte
+}
+
+// fakeServer implements org.freedesktop.Notifications.
+type fakeServer struct {
+	received chan receivedNotification
+}
+
+func (f *fakeServer) Notify(appName string, replacesId uint32, appIcon, summary, body string, actions []string, hints map[string]dbus.Variant, timeout int32) (uint32, *dbus.Error) {
+	n := receivedNotification{appName: appName, summary: summary, body: body}
+	if v, ok := hints["urgency"]; ok {
+		n.urgency, _ = v.Value().(byte)
+	}
+	f.received <- n
+	return 1, nil
+}
+
+func startFakeServer(t *testing.T, addr string) *fakeServer {
+	t.Helper()
+	conn, err := dbus.Connect(addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	server := &fakeServer{received: make(chan receivedNotification, 10)}
+	require.NoError(t, conn.Export(server, "/org/freedesktop/Notifications", "org.freedesktop.Notifications"))
+	reply, err := conn.RequestName("org.freedesktop.Notifications", dbus.NameFlagDoNotQueue)
+	require.NoError(t, err)
+	require.Equal(t, dbus.RequestNameReplyPrimaryOwner, reply)
+	return server
+}
+
+func TestDBusNotifier(t *testing.T) {
+	addr := startBus(t)
+	server := startFakeServer(t, addr)
+
+	notifier, err := notify.NewDBusNotifier(notify.WithBusAddress(addr))
+	require.NoError(t, err)
+	defer notifier.Close()
+
+	ctx, ca := context.WithTimeout(context.Background(), 5*time.Second)
+	defer ca()
+	require.NoError(t, notifier.Notify(ctx, notify.Notification{
+		Summary: "toon1 entered Bullion Mint",
+		Body:    "Bullion Mint, Cashbot HQ",
+		Urgency: notify.UrgencyCritical,
+	}))
+	assert.Equal(t, receivedNotification{
+		appName: "ttr",
+		summary: "toon1 entered Bullion Mint",
+		body:    "Bullion Mint, Cashbot HQ",
+		urgency: byte(notify.UrgencyCritical),
+	}, <-server.received)
+}
+
+func TestDBusNotifierNoServer(t *testing.T) {
+	addr := startBus(t)
+	notifier, err := notify.NewDBusNotifier(notify.WithBusAddress(addr))
+	require.NoError(t, err)
+	defer notifier.Close()
+
+	err = notifier.Notify(context.Background(), notify.Notification{Summary: "test"})
+	assert.Error(t, err)
+}
+
+type recorder struct {
+	mu            sync.Mutex
+	notifications []notify.Notification
+}
+
+func (r *recorder) Notify(_ context.Context, n notify.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notifications = append(r.notifications, n)
+	return nil
+}
+
+func TestSink(t *testing.T) {
+	rec := &recorder{}
+	sink := notify.NewSink(rec, append(notify.DefaultRules,
+		notify.Rule{Event: notify.EventZone, Location: "loopy lane"},
+	))
+
+	r, w := io.Pipe()
+	tracker := game.NewStatusTracker(r)
+	sink.Attach("toon1", tracker)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tracker.Run()
+	}()
+	for _, line := range []string{
+		`:vlt: enter(requestStatus={'loader': 'safeZoneLoader', 'where': 'playground', 'how': 'teleportIn', 'hoodId': 2000, 'zoneId': 2000})`,
+		`:vlt: enter(requestStatus={'loader': 'townLoader', 'where': 'street', 'how': 'walk', 'hoodId': 2000, 'zoneId': 2213})`,
+		`:vlt: enter(requestStatus={'loader': 'cogHQLoader', 'where': 'mintInterior', 'how': 'teleportIn', 'zoneId': 12701, 'mintId': 12700, 'hoodId': 12000})`,
+		`:vlt: stageId 12700, floor 4, [0, 18, 7, 13, 3, 24]`,
+		`:TTRClientRepository: Lost connection to gameserver.`,
+	} {
+		w.Write([]byte(line + "\n"))
+	}
+	w.Close()
+	<-done
+
+	// the sink sends notifications in the background
+	require.True(t, sink.Wait(5*time.Second))
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	assert.Equal(t, []notify.Notification{
+		{Summary: "toon1 entered Loopy Lane", Body: "Loopy Lane, Toontown Central", Urgency: notify.UrgencyNormal},
+		{Summary: "toon1 entered Bullion Mint", Body: "Bullion Mint, Cashbot HQ", Urgency: notify.UrgencyNormal},
+		{Summary: "toon1 disconnected", Body: "Lost connection to gameserver.", Urgency: notify.UrgencyCritical},
+	}, rec.notifications)
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := notify.ParseRules(nil)
+	require.NoError(t, err)
+	assert.Equal(t, notify.DefaultRules, rules)
+
+	rules, err = notify.ParseRules([]map[string]string{
+		{"event": "zone", "Location": "Loopy Lane"},
+		{"event": "disconnect"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []notify.Rule{
+		{Event: notify.EventZone, Location: "Loopy Lane"},
+		{Event: notify.EventDisconnect},
+	}, rules)
+
+	_, err = notify.ParseRules([]map[string]string{{"event": "zone", "district": "Gulp Gulch"}})
+	assert.Error(t, err)
+	_, err = notify.ParseRules([]map[string]string{{"event": "disconnect", "hood": "Cashbot HQ"}})
+	assert.Error(t, err)
+}
+
+func TestRuleValidate(t *testing.T) {
+	assert.NoError(t, notify.Rule{Event: notify.EventZone, Where: "mintInterior"}.Validate())
+	assert.NoError(t, notify.Rule{Event: notify.EventDisconnect}.Validate())
+	assert.Error(t, notify.Rule{Event: notify.EventDisconnect, Hood: "Cashbot HQ"}.Validate())
+	assert.Error(t, notify.Rule{Event: "teleport"}.Validate())
+}
@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+)
+
+type EventType string
+
+const (
+	// The toon entered a zone.
+	EventZone EventType = "zone"
+	// The game lost its connection to the server.
+	EventDisconnect EventType = "disconnect"
+)
+
+// Rule decides which events cause a notification. All of the rule's
+// non-empty fields must match (case-insensitively) for it to apply.
+type Rule struct {
+	Event EventType
+	// The "where" of the zone's request status, e.g. "mintInterior" or
+	// "playground".
+	Where string
+	// The name of the zone, e.g. "Bullion Mint" or "Loopy Lane".
+	Location string
+	// The name of the playground, e.g. "Cashbot HQ".
+	Hood string
+}
+
+// DefaultRules notify when a toon enters a mint, and when it disconnects.
+var DefaultRules = []Rule{
+	{Event: EventZone, Where: "mintInterior"},
+	{Event: EventDisconnect},
+}
+
+// Event is a game event that may cause a notification.
+type Event struct {
+	Type     EventType
+	Account  string
+	Where    string
+	Location string
+	Hood     string
+	// For disconnect events, the message that was logged.
+	Message string
+}
+
+// ParseRules builds rules from their fields, as returned by
+// config.NotificationRules. Keys are matched case-insensitively. If fields is
+// nil, DefaultRules is returned.
+func ParseRules(fields []map[string]string) ([]Rule, error) {
+	if fields == nil {
+		return DefaultRules, nil
+	}
+	rules := make([]Rule, len(fields))
+	for i, f := range fields {
+		for k, v := range f {
+			switch strings.ToLower(k) {
+			case "event":
+				rules[i].Event = EventType(v)
+			case "where":
+				rules[i].Where = v
+			case "location":
+				rules[i].Location = v
+			case "hood":
+				rules[i].Hood = v
+			default:
+				return nil, fmt.Errorf("rule %d: unknown field %q", i, k)
+			}
+		}
+		if err := rules[i].Validate(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+	}
+	return rules, nil
+}
+
+func (r Rule) Validate() error {
+	switch r.Event {
+	case EventZone:
+	case EventDisconnect:
+		if r.Where != "" || r.Location != "" || r.Hood != "" {
+			return fmt.Errorf("disconnect rules cannot match a zone")
+		}
+	default:
+		return fmt.Errorf("invalid event %q (expected %s or %s)", r.Event, EventZone, EventDisconnect)
+	}
+	return nil
+}
+
+func (r Rule) Matches(ev Event) bool {
+	matches := func(want, got string) bool {
+		return want == "" || strings.EqualFold(want, got)
+	}
+	return r.Event == ev.Type &&
+		matches(r.Where, ev.Where) &&
+		matches(r.Location, ev.Location) &&
+		matches(r.Hood, ev.Hood)
+}
+
+// Notification returns the notification for an event.
+func (ev Event) Notification() Notification {
+	switch ev.Type {
+	case EventDisconnect:
+		return Notification{
+			Summary: fmt.Sprintf("%s disconnected", ev.Account),
+			Body:    ev.Message,
+			Urgency: UrgencyCritical,
+		}
+	default:
+		body := ev.Location
+		if ev.Hood != "" && ev.Hood != ev.Location {
+			body += ", " + ev.Hood
+		}
+		return Notification{
+			Summary: fmt.Sprintf("%s entered %s", ev.Account, ev.Location),
+			Body:    body,
+			Urgency: UrgencyNormal,
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kralicky/ttr/pkg/game"
+	"github.com/kralicky/ttr/pkg/gamelog"
+	"github.com/kralicky/ttr/pkg/zones"
+	log "github.com/sirupsen/logrus"
+)
+
+// notifyTimeout limits how long a notification can take to send, so that a
+// slow notification server can't hold up the following events.
+const notifyTimeout = 5 * time.Second
+
+// Sink sends notifications for the events of one or more games.
+type Sink struct {
+	notifier Notifier
+	rules    []Rule
+	wg       sync.WaitGroup
+}
+
+func NewSink(notifier Notifier, rules []Rule) *Sink {
+	return &Sink{
+		notifier: notifier,
+		rules:    rules,
+	}
+}
+
+// Attach subscribes to the tracker's events for the given account, until the
+// tracker stops. It can be used as a game.WithTrackerHook.
+func (s *Sink) Attach(account string, tracker *game.StatusTracker) {
+	sub := tracker.Subscribe(game.SubscribeOptions{
+		Buffer: 64,
+		Filter: func(ev game.TrackerEvent) bool {
+			return ev.Kind == game.ZoneEvent || gamelog.DisconnectMatcher.Match(ev.Line) != nil
+		},
+	})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for te := range sub.C {
+			ev := Event{Account: account}
+			switch te.Kind {
+			case game.ZoneEvent:
+				ev.Type = EventZone
+				ev.Where = te.Zone.Where
+				ev.Location = game.LocationName(te.Zone)
+				if te.Zone.HoodId != nil {
+					ev.Hood = zones.HoodName(*te.Zone.HoodId)
+				}
+			case game.LogEvent:
+				ev.Type = EventDisconnect
+				if events := gamelog.DisconnectMatcher.Match(te.Line); len(events) > 0 {
+					ev.Message = events[0].(gamelog.Disconnect).Message
+				}
+			}
+			s.handle(ev)
+		}
+		if dropped := sub.Dropped(); dropped > 0 {
+			log.Warnf("notifications: dropped %d events for %s", dropped, account)
+		}
+	}()
+}
+
+// Wait waits for the events of every attached tracker to be handled, after
+// the trackers have stopped, so that the notifier isn't closed while the last
+// notifications are being sent. It gives up after the timeout, and returns
+// whether all events were handled.
+func (s *Sink) Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (s *Sink) handle(ev Event) {
+	for _, r := range s.rules {
+		if !r.Matches(ev) {
+			continue
+		}
+		ctx, ca := context.WithTimeout(context.Background(), notifyTimeout)
+		err := s.notifier.Notify(ctx, ev.Notification())
+		ca()
+		if err != nil {
+			log.Warnf("failed to send notification: %v", err)
+		}
+		// only notify once per event
+		return
+	}
+}
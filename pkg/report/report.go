@@ -0,0 +1,357 @@
+// Package report builds session timelines from game log files.
+package report
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kralicky/ttr/pkg/game"
+	"github.com/kralicky/ttr/pkg/gamelog"
+	"github.com/kralicky/ttr/pkg/zones"
+)
+
+// Duration is a time.Duration that is encoded as a string, e.g. "1m30s".
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// Session is the timeline of a single game process, read from its log file.
+type Session struct {
+	LogFile string    `json:"logFile"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	// Whether the log contains timestamps. Logs written by older versions of
+	// the launcher do not, in which case only the session's start and end
+	// times are known, and all other times and durations are zero.
+	Timed       bool         `json:"timed"`
+	Visits      []Visit      `json:"visits"`
+	Runs        []Run        `json:"runs"`
+	Hoods       []HoodTime   `json:"hoods"`
+	Disconnects []Disconnect `json:"disconnects"`
+}
+
+func (s *Session) Duration() Duration {
+	if s.Start.IsZero() || s.End.Before(s.Start) {
+		return 0
+	}
+	return Duration(s.End.Sub(s.Start))
+}
+
+// Visit is a stay in a single zone.
+type Visit struct {
+	Start    time.Time `json:"start"`
+	Duration Duration  `json:"duration"`
+	Location string    `json:"location"`
+	Hood     string    `json:"hood,omitempty"`
+	Where    string    `json:"where"`
+}
+
+type RunKind string
+
+const (
+	Facility   RunKind = "facility"
+	BossBattle RunKind = "bossBattle"
+)
+
+// Outcome is how a Cog HQ run ended. The game does not log the result of a
+// run, so it is inferred from where the toon went next: finishing a run
+// returns the toon to the same Cog HQ, while going sad sends it to a
+// playground.
+type Outcome string
+
+const (
+	Completed    Outcome = "completed"
+	Left         Outcome = "left"
+	Disconnected Outcome = "disconnected"
+	// The log ended during the run.
+	Unfinished Outcome = "unfinished"
+)
+
+// Run is a Cog HQ facility (e.g. a mint) or boss battle.
+type Run struct {
+	Kind     RunKind   `json:"kind"`
+	Name     string    `json:"name"`
+	Hood     string    `json:"hood,omitempty"`
+	Start    time.Time `json:"start"`
+	Duration Duration  `json:"duration"`
+	Floors   []Floor   `json:"floors,omitempty"`
+	Outcome  Outcome   `json:"outcome"`
+
+	where  string
+	hoodId int64
+}
+
+type Floor struct {
+	StageId int `json:"stageId"`
+	// 1-based floor number
+	Floor   int   `json:"floor"`
+	RoomIds []int `json:"roomIds"`
+}
+
+type HoodTime struct {
+	Hood     string   `json:"hood"`
+	Duration Duration `json:"duration"`
+}
+
+type Disconnect struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// Options are used when replaying a log.
+type Options struct {
+	// Name of the log file.
+	Name string
+	// Start and end of the session, if known. Timestamps in the log take
+	// precedence.
+	Start, End time.Time
+}
+
+// ReplayFile replays a log file written by the launcher. The session's start
+// time is taken from the file name (ttr-<unix timestamp>.log), and its end
+// time from the file's modification time.
+func ReplayFile(path string) (*Session, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return Replay(f, Options{
+		Name:  filepath.Base(path),
+		Start: LogFileTime(path),
+		End:   fi.ModTime(),
+	}), nil
+}
+
+// LogFileTime returns the time the game was started, from the name of a log
+// file written by the launcher. If the name is not in the expected format,
+// the zero time is returned.
+func LogFileTime(path string) time.Time {
+	name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "ttr-"), ".log")
+	ts, err := strconv.ParseInt(name, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(ts, 0)
+}
+
+// Replay reads a game log through a StatusTracker and builds the session's
+// timeline.
+func Replay(r io.Reader, opts Options) *Session {
+	tracker := game.NewStatusTracker(r)
+	// replaying a file has no time pressure, so nothing should be dropped
+	sub := tracker.Subscribe(game.SubscribeOptions{Policy: game.PolicyBlock})
+	go tracker.Run()
+
+	b := &builder{
+		session: &Session{
+			LogFile:     opts.Name,
+			Start:       opts.Start,
+			Visits:      []Visit{},
+			Runs:        []Run{},
+			Hoods:       []HoodTime{},
+			Disconnects: []Disconnect{},
+		},
+		now:      opts.Start,
+		registry: gamelog.NewRegistry(gamelog.FloorInfoMatcher, gamelog.DisconnectMatcher),
+	}
+	for ev := range sub.C {
+		switch ev.Kind {
+		case game.ZoneEvent:
+			b.enterZone(ev.Zone)
+		case game.LogEvent:
+			b.logLine(ev.Line)
+		}
+	}
+
+	if !b.session.Timed && opts.End.After(b.now) {
+		b.now = opts.End
+	}
+	b.session.End = b.now
+	b.endVisit()
+	b.endRun(Unfinished)
+	b.sumHoods()
+	return b.session
+}
+
+type builder struct {
+	session  *Session
+	now      time.Time
+	registry *gamelog.Registry
+	visit    *Visit
+	run      *Run
+}
+
+func (b *builder) logLine(line string) {
+	if t, ok := gamelog.ParseTimestamp(line); ok {
+		if !b.session.Timed {
+			b.session.Timed = true
+			if b.session.Start.IsZero() {
+				b.session.Start = t
+			}
+		}
+		b.now = t
+		return
+	}
+	for _, ev := range b.registry.Match(line) {
+		switch ev := ev.(type) {
+		case gamelog.FloorInfo:
+			b.floorInfo(ev)
+		case gamelog.Disconnect:
+			b.session.Disconnects = append(b.session.Disconnects, Disconnect{
+				Time:    b.timestamp(),
+				Message: ev.Message,
+			})
+			b.endVisit()
+			b.endRun(Disconnected)
+		}
+	}
+}
+
+// timestamp returns the current time, or the zero time if the log is not
+// timed.
+func (b *builder) timestamp() time.Time {
+	if !b.session.Timed {
+		return time.Time{}
+	}
+	return b.now
+}
+
+func (b *builder) enterZone(req game.EnterRequestStatus) {
+	b.endVisit()
+	var hoodId int64
+	if req.HoodId != nil {
+		hoodId = *req.HoodId
+	}
+	b.visit = &Visit{
+		Start:    b.timestamp(),
+		Location: game.LocationName(req),
+		Hood:     zones.HoodName(hoodId),
+		Where:    req.Where,
+	}
+
+	kind, isRun := runKind(req.Where)
+	if b.run != nil {
+		if isRun && kind == b.run.Kind && strings.EqualFold(req.Where, b.run.where) && hoodId == b.run.hoodId {
+			// the next floor of a multi-floor facility
+			return
+		}
+		if hoodId != 0 && hoodId == b.run.hoodId {
+			b.endRun(Completed)
+		} else {
+			b.endRun(Left)
+		}
+	}
+	if !isRun {
+		return
+	}
+	b.run = &Run{
+		Kind:   kind,
+		Name:   b.visit.Location,
+		Hood:   b.visit.Hood,
+		Start:  b.timestamp(),
+		where:  req.Where,
+		hoodId: hoodId,
+	}
+	if kind == BossBattle && b.run.Hood != "" {
+		b.run.Name = b.run.Hood + " boss battle"
+	}
+}
+
+func (b *builder) floorInfo(info gamelog.FloorInfo) {
+	if b.run == nil || b.run.Kind != Facility {
+		return
+	}
+	// the request status only identifies mints, but the floor info
+	// identifies every facility
+	if z, ok := zones.Lookup(int64(info.StageId)); ok && z.Kind == zones.KindFacility && len(b.run.Floors) == 0 {
+		b.run.Name = z.Name
+	}
+	b.run.Floors = append(b.run.Floors, Floor{
+		StageId: info.StageId,
+		Floor:   info.Floor + 1,
+		RoomIds: info.RoomIds,
+	})
+}
+
+func (b *builder) endVisit() {
+	if b.visit == nil {
+		return
+	}
+	if b.session.Timed {
+		b.visit.Duration = Duration(b.now.Sub(b.visit.Start))
+	}
+	b.session.Visits = append(b.session.Visits, *b.visit)
+	b.visit = nil
+}
+
+func (b *builder) endRun(outcome Outcome) {
+	if b.run == nil {
+		return
+	}
+	if b.session.Timed {
+		b.run.Duration = Duration(b.now.Sub(b.run.Start))
+	}
+	b.run.Outcome = outcome
+	b.session.Runs = append(b.session.Runs, *b.run)
+	b.run = nil
+}
+
+func (b *builder) sumHoods() {
+	if !b.session.Timed {
+		return
+	}
+	totals := map[string]Duration{}
+	for _, v := range b.session.Visits {
+		if v.Hood != "" {
+			totals[v.Hood] += v.Duration
+		}
+	}
+	for hood, d := range totals {
+		b.session.Hoods = append(b.session.Hoods, HoodTime{Hood: hood, Duration: d})
+	}
+	slices.SortFunc(b.session.Hoods, func(a, b HoodTime) int {
+		if c := cmp.Compare(b.Duration, a.Duration); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Hood, b.Hood)
+	})
+}
+
+// runKind returns the kind of run for the "where" of a request status.
+func runKind(where string) (RunKind, bool) {
+	if _, ok := gamelog.FacilityForWhere(where); ok {
+		return Facility, true
+	}
+	if strings.EqualFold(where, gamelog.BossBattleWhere) {
+		return BossBattle, true
+	}
+	return "", false
+}
+
+// FloorList returns the floors of the run, e.g. "4, 5".
+func (r Run) FloorList() string {
+	var floors []string
+	for _, f := range r.Floors {
+		floors = append(floors, fmt.Sprint(f.Floor))
+	}
+	return strings.Join(floors, ", ")
+}
@@ -0,0 +1,96 @@
+package report_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kralicky/ttr/pkg/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func at(minute int) time.Time {
+	return time.Date(2024, 6, 10, 18, minute, 0, 0, time.UTC)
+}
+
+func TestReplay(t *testing.T) {
+	f, err := os.Open("testdata/timed.log")
+	require.NoError(t, err)
+	defer f.Close()
+
+	s := report.Replay(f, report.Options{Name: "timed.log", End: at(31)})
+	assert.True(t, s.Timed)
+	assert.Equal(t, at(0), s.Start)
+	// the last timestamp is used rather than the given end time
+	assert.Equal(t, at(30), s.End)
+	assert.Equal(t, report.Duration(30*time.Minute), s.Duration())
+
+	type visit struct {
+		location string
+		start    time.Time
+		duration time.Duration
+	}
+	var visits []visit
+	for _, v := range s.Visits {
+		visits = append(visits, visit{v.Location, v.Start, time.Duration(v.Duration)})
+	}
+	assert.Equal(t, []visit{
+		{"Toontown Central", at(0), 2 * time.Minute},
+		{"Loopy Lane", at(2), 3 * time.Minute},
+		{"Cashbot HQ", at(5), time.Minute},
+		{"Bullion Mint", at(6), 15 * time.Minute},
+		{"Cashbot HQ", at(21), time.Minute},
+		{"Coin Mint", at(22), 3 * time.Minute},
+		{"The Brrrgh", at(25), 5 * time.Minute},
+	}, visits)
+
+	require.Len(t, s.Runs, 2)
+	assert.Equal(t, "Bullion Mint", s.Runs[0].Name)
+	assert.Equal(t, report.Facility, s.Runs[0].Kind)
+	assert.Equal(t, report.Completed, s.Runs[0].Outcome)
+	assert.Equal(t, report.Duration(15*time.Minute), s.Runs[0].Duration)
+	assert.Equal(t, []report.Floor{{StageId: 12700, Floor: 5, RoomIds: []int{0, 18, 7, 13, 3, 24}}}, s.Runs[0].Floors)
+	assert.Equal(t, "Coin Mint", s.Runs[1].Name)
+	assert.Equal(t, report.Left, s.Runs[1].Outcome)
+	assert.Equal(t, "1", s.Runs[1].FloorList())
+
+	assert.Equal(t, []report.HoodTime{
+		{Hood: "Cashbot HQ", Duration: report.Duration(20 * time.Minute)},
+		{Hood: "The Brrrgh", Duration: report.Duration(5 * time.Minute)},
+		{Hood: "Toontown Central", Duration: report.Duration(5 * time.Minute)},
+	}, s.Hoods)
+
+	require.Len(t, s.Disconnects, 1)
+	assert.Equal(t, at(30), s.Disconnects[0].Time)
+	assert.Equal(t, "Lost connection to gameserver.", s.Disconnects[0].Message)
+}
+
+func TestReplayUntimed(t *testing.T) {
+	f, err := os.Open("../gamelog/testdata/mint.log")
+	require.NoError(t, err)
+	defer f.Close()
+
+	s := report.Replay(f, report.Options{Name: "mint.log", Start: at(0), End: at(10)})
+	assert.False(t, s.Timed)
+	assert.Equal(t, report.Duration(10*time.Minute), s.Duration())
+	require.NotEmpty(t, s.Visits)
+	for _, v := range s.Visits {
+		assert.Zero(t, v.Start)
+		assert.Zero(t, v.Duration)
+	}
+	assert.Empty(t, s.Hoods)
+
+	require.Len(t, s.Runs, 2)
+	assert.Equal(t, "Bullion Mint", s.Runs[0].Name)
+	assert.Equal(t, "5, 1", s.Runs[0].FloorList())
+	assert.Equal(t, report.Completed, s.Runs[0].Outcome)
+	assert.Equal(t, report.BossBattle, s.Runs[1].Kind)
+	assert.Equal(t, "Cashbot HQ boss battle", s.Runs[1].Name)
+	assert.Equal(t, report.Unfinished, s.Runs[1].Outcome)
+}
+
+func TestLogFileTime(t *testing.T) {
+	assert.Equal(t, time.Unix(1718042400, 0), report.LogFileTime("/data/logs/ttr-1718042400.log"))
+	assert.True(t, report.LogFileTime("game.log").IsZero())
+}
@@ -0,0 +1,136 @@
+// Package termimage writes images to terminals that support inline graphics.
+package termimage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/png"
+	"io"
+)
+
+// kittyChunkSize is the maximum size of the base64 payload of a single kitty
+// graphics escape sequence.
+const kittyChunkSize = 4096
+
+// WriteKitty writes the image using the kitty graphics protocol.
+func WriteKitty(w io.Writer, img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+	bw := bufio.NewWriter(w)
+	for first := true; first || len(data) > 0; first = false {
+		chunk := data[:min(len(data), kittyChunkSize)]
+		data = data[len(chunk):]
+		more := 0
+		if len(data) > 0 {
+			more = 1
+		}
+		if first {
+			// a=T: transmit and display, f=100: png data
+			fmt.Fprintf(bw, "\x1b_Ga=T,f=100,m=%d;%s\x1b\\", more, chunk)
+		} else {
+			fmt.Fprintf(bw, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	bw.WriteString("\n")
+	return bw.Flush()
+}
+
+// WriteITerm writes the image using the iTerm2 inline images protocol.
+func WriteITerm(w io.Writer, img image.Image, name string) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\x1b]1337;File=name=%s;size=%d;inline=1;preserveAspectRatio=1:%s\a\n",
+		base64.StdEncoding.EncodeToString([]byte(name)),
+		buf.Len(),
+		base64.StdEncoding.EncodeToString(buf.Bytes()),
+	)
+	return err
+}
+
+// WriteSixel writes the image as sixel graphics. The image is reduced to a
+// 256 color palette, and transparent areas are drawn black.
+func WriteSixel(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	opaque := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(opaque, opaque.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	draw.Draw(opaque, opaque.Bounds(), img, bounds.Min, draw.Over)
+	p := image.NewPaletted(opaque.Bounds(), palette.Plan9)
+	draw.Draw(p, p.Bounds(), opaque, image.Point{}, draw.Src)
+
+	bw := bufio.NewWriter(w)
+	// DCS q, then the raster attributes: 1:1 pixel aspect ratio and size
+	fmt.Fprintf(bw, "\x1bPq\"1;1;%d;%d", width, height)
+
+	used := make([]bool, len(p.Palette))
+	for _, idx := range p.Pix {
+		used[idx] = true
+	}
+	for i, c := range p.Palette {
+		if !used[i] {
+			continue
+		}
+		r, g, b, _ := c.RGBA()
+		fmt.Fprintf(bw, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, b*100/0xffff)
+	}
+
+	row := make([]byte, width)
+	for y := 0; y < height; y += 6 {
+		// colors used in this band of 6 rows
+		bandColors := make([]bool, len(p.Palette))
+		for dy := 0; dy < 6 && y+dy < height; dy++ {
+			for x := 0; x < width; x++ {
+				bandColors[p.ColorIndexAt(x, y+dy)] = true
+			}
+		}
+		for c, inBand := range bandColors {
+			if !inBand {
+				continue
+			}
+			for x := 0; x < width; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && y+dy < height; dy++ {
+					if int(p.ColorIndexAt(x, y+dy)) == c {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = '?' + bits
+			}
+			fmt.Fprintf(bw, "#%d", c)
+			writeSixelRow(bw, row)
+			// return to the start of the band for the next color
+			bw.WriteByte('$')
+		}
+		bw.WriteByte('-')
+	}
+	bw.WriteString("\x1b\\\n")
+	return bw.Flush()
+}
+
+// writeSixelRow writes a row of sixel characters, run-length encoding
+// repeated characters.
+func writeSixelRow(bw *bufio.Writer, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			fmt.Fprintf(bw, "!%d%c", n, row[i])
+		} else {
+			bw.Write(row[i:j])
+		}
+		i = j
+	}
+}
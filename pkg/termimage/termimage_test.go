@@ -0,0 +1,86 @@
+package termimage_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/kralicky/ttr/pkg/termimage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+func TestWriteKitty(t *testing.T) {
+	// large enough to need several chunks
+	img := image.NewRGBA(image.Rect(0, 0, 200, 200))
+	rand.New(rand.NewSource(1)).Read(img.Pix)
+	var buf bytes.Buffer
+	require.NoError(t, termimage.WriteKitty(&buf, img))
+
+	chunks := regexp.MustCompile("\x1b_G([^;]*);([^\x1b]*)\x1b\\\\").FindAllStringSubmatch(buf.String(), -1)
+	require.Greater(t, len(chunks), 1)
+	assert.Equal(t, "a=T,f=100,m=1", chunks[0][1])
+	assert.Equal(t, "m=0", chunks[len(chunks)-1][1])
+	var data string
+	for _, c := range chunks {
+		assert.LessOrEqual(t, len(c[2]), 4096)
+		data += c[2]
+	}
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	require.NoError(t, err)
+	out, err := png.Decode(bytes.NewReader(decoded))
+	require.NoError(t, err)
+	assert.Equal(t, img.Bounds(), out.Bounds())
+}
+
+func TestWriteITerm(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, termimage.WriteITerm(&buf, testImage(4, 4), "map.png"))
+	s := buf.String()
+	require.True(t, strings.HasPrefix(s, "\x1b]1337;File=name="+base64.StdEncoding.EncodeToString([]byte("map.png"))+";"))
+	require.True(t, strings.HasSuffix(s, "\a\n"))
+	_, data, ok := strings.Cut(strings.TrimSuffix(s, "\a\n"), ":")
+	require.True(t, ok)
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	require.NoError(t, err)
+	_, err = png.Decode(bytes.NewReader(decoded))
+	require.NoError(t, err)
+}
+
+func TestWriteSixel(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, termimage.WriteSixel(&buf, testImage(8, 7)))
+	s := buf.String()
+	require.True(t, strings.HasPrefix(s, "\x1bPq\"1;1;8;7#"))
+	require.True(t, strings.HasSuffix(s, "\x1b\\\n"))
+	// two colors, in two bands of 6 rows
+	assert.Equal(t, 2, strings.Count(s, "-"))
+	assert.Equal(t, 2, strings.Count(s, ";2;"))
+	// the first band is 4 red columns of 6 pixels followed by 4 blue
+	// columns, drawn one color at a time (with run-length encoding)
+	assert.Contains(t, s, "!4~!4?$")
+	assert.Contains(t, s, "!4?!4~$")
+	// the second band has a single row of pixels
+	assert.Contains(t, s, "!4@!4?$")
+	assert.Contains(t, s, "!4?!4@$")
+}
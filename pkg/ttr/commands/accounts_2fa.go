@@ -1,9 +1,13 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/jedib0t/go-pretty/v6/text"
 	"github.com/kralicky/ttr/pkg/auth"
 	"github.com/spf13/cobra"
 )
@@ -17,16 +21,31 @@ func BuildTwoFactorAuthCmd() *cobra.Command {
 	cmd.AddCommand(BuildSetupTwoFactorAuthCmd())
 	cmd.AddCommand(BuildForgetTwoFactorAuthCmd())
 	cmd.AddCommand(BuildGenerateCodeCmd())
+	cmd.AddCommand(BuildShowQRCodeCmd())
+	cmd.AddCommand(BuildRecoveryCodesCmd())
 
 	return cmd
 }
 
 func BuildSetupTwoFactorAuthCmd() *cobra.Command {
+	var force bool
+	var uri string
+	var skipVerify bool
+	var showQR bool
 	cmd := &cobra.Command{
 		Use:   "setup <username>",
 		Short: "set up two-factor authentication for an account",
-		Args:  cobra.ExactArgs(1),
+		Long: `Set up two-factor authentication for an account.
+
+The secret can be entered as the code shown on the TTR account page (spaces
+are ignored), or as an otpauth:// URI. QR code images are not read; click
+"QR code not working?" under the QR code to show the code instead.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if auth.HasTwoFactorAuthSecret(args[0]) && !force {
+				return fmt.Errorf("a two-factor auth secret is already stored for %s (use --force to replace it)", args[0])
+			}
+
 			var confirm bool
 			survey.AskOne(&survey.Confirm{
 				Message: `
@@ -47,22 +66,58 @@ Do you understand the potential risks, and wish to continue?`[1:],
 				return nil
 			}
 
-			var secret string
-			if err := survey.AskOne(&survey.Password{
-				Message: "Enter the Two-Step Login code given by the TTR account page:",
-				Help:    "This can be found by clicking \"QR code not working?\" under the QR code.",
-			}, &secret); err != nil {
+			input := uri
+			if input == "" {
+				if err := survey.AskOne(&survey.Password{
+					Message: "Enter the Two-Step Login code given by the TTR account page:",
+					Help:    "This can be found by clicking \"QR code not working?\" under the QR code. An otpauth:// URI is also accepted.",
+				}, &input, survey.WithValidator(func(ans interface{}) error {
+					_, err := auth.ParseTwoFactorSecret(ans.(string))
+					return err
+				})); err != nil {
+					return err
+				}
+			}
+			secret, err := auth.ParseTwoFactorSecret(input)
+			if err != nil {
 				return err
 			}
 
-			err := auth.SetTwoFactorAuthSecret(args[0], secret)
-			if err != nil {
+			if !skipVerify {
+				var code string
+				if err := survey.AskOne(&survey.Input{
+					Message: "Enter the current code from your authenticator app (leave empty to skip):",
+				}, &code); err != nil {
+					return err
+				}
+				if code != "" && !auth.ValidateTwoFactorAuthCode(secret, code) {
+					return errors.New("the code does not match the secret; check that the secret was entered correctly and that your clock is accurate")
+				}
+			}
+
+			if err := auth.ReplaceTwoFactorAuthSecret(args[0], secret); err != nil {
 				return err
 			}
+			if !auth.HasRecoveryCodes(args[0]) {
+				fmt.Printf("Tip: run `ttr accounts 2fa recovery add %s` to store your recovery codes.\n", args[0])
+			}
+
+			if !showQR {
+				if err := survey.AskOne(&survey.Confirm{
+					Message: "Secret saved. Show a QR code for adding it to another authenticator app?",
+				}, &showQR); err != nil {
+					return err
+				}
+			}
+			if showQR {
+				if err := printTwoFactorQRCode(args[0]); err != nil {
+					return err
+				}
+			}
 
 			var generateTestCode bool
 			if err := survey.AskOne(&survey.Confirm{
-				Message: "Secret saved. Generate a test code?",
+				Message: "Generate a test code?",
 				Default: true,
 			}, &generateTestCode); err != nil {
 				return err
@@ -79,10 +134,41 @@ Do you understand the potential risks, and wish to continue?`[1:],
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&force, "force", false, "replace an existing secret (e.g. after rotating it on the TTR account page)")
+	cmd.Flags().StringVar(&uri, "uri", "", "otpauth:// URI containing the secret")
+	cmd.Flags().BoolVar(&skipVerify, "skip-verify", false, "do not ask for a code from an authenticator app to verify the secret")
+	cmd.Flags().BoolVar(&showQR, "show-qr", false, "print a QR code for enrolling another authenticator app")
 
 	return cmd
 }
 
+func BuildShowQRCodeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "qr <username>",
+		Short: "print a QR code for adding a stored secret to an authenticator app",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return printTwoFactorQRCode(args[0])
+		},
+	}
+
+	return cmd
+}
+
+func printTwoFactorQRCode(account string) error {
+	uri, err := auth.TwoFactorAuthURI(account)
+	if err != nil {
+		return fmt.Errorf("failed to read two-factor auth secret: %w", err)
+	}
+	qr, err := auth.RenderQRCode(uri)
+	if err != nil {
+		return err
+	}
+	fmt.Print(qr)
+	fmt.Println("Scan this code with your authenticator app. Anyone who can see it can generate codes for your account!")
+	return nil
+}
+
 func BuildForgetTwoFactorAuthCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "forget <username>",
@@ -123,11 +209,15 @@ func BuildForgetTwoFactorAuthCmd() *cobra.Command {
 }
 
 func BuildGenerateCodeCmd() *cobra.Command {
+	var watch bool
 	cmd := &cobra.Command{
 		Use:   "generate <username>",
 		Short: "generate a two-factor authentication code",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if watch {
+				return watchCodes(cmd.Context(), args[0])
+			}
 			code, err := auth.GenerateTwoFactorAuthCode(args[0])
 			if err != nil {
 				return fmt.Errorf("failed to generate a two-factor auth code: %w", err)
@@ -137,6 +227,31 @@ func BuildGenerateCodeCmd() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "keep generating codes, with a countdown until each one expires")
 
 	return cmd
 }
+
+func watchCodes(ctx context.Context, account string) error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		now := auth.Now()
+		code, err := auth.GenerateTwoFactorAuthCodeAt(account, now)
+		if err != nil {
+			return fmt.Errorf("failed to generate a two-factor auth code: %w", err)
+		}
+		remaining := auth.CodeExpiresIn(now).Round(time.Second)
+		color := text.Colors{text.FgGreen}
+		if remaining < auth.MinCodeValidity {
+			color = text.Colors{text.FgRed}
+		}
+		fmt.Printf("\r\033[KYour code is: %s %s", text.Bold.Sprint(code), color.Sprintf("(expires in %2ds)", int(remaining.Seconds())))
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
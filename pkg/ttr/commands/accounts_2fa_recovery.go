@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/kralicky/ttr/pkg/auth"
+	"github.com/spf13/cobra"
+)
+
+func BuildRecoveryCodesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recovery",
+		Short: "manage two-factor authentication recovery codes",
+		Long: `Manage two-factor authentication recovery codes.
+
+Recovery codes are stored alongside the account's other secrets. When a
+two-factor auth code is rejected during launch, you will be offered the
+option to log in with a stored recovery code instead.`,
+	}
+
+	cmd.AddCommand(BuildAddRecoveryCodesCmd())
+	cmd.AddCommand(BuildListRecoveryCodesCmd())
+	cmd.AddCommand(BuildUseRecoveryCodeCmd())
+
+	return cmd
+}
+
+func BuildAddRecoveryCodesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <username> [code...]",
+		Short: "store recovery codes for an account",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			codes := args[1:]
+			if len(codes) == 0 {
+				var input string
+				if err := survey.AskOne(&survey.Multiline{
+					Message: "Enter recovery codes, one per line:",
+				}, &input); err != nil {
+					return err
+				}
+				codes = strings.Fields(input)
+			}
+			if len(codes) == 0 {
+				return errors.New("no recovery codes given")
+			}
+			added, err := auth.AddRecoveryCodes(args[0], codes...)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Added %d recovery code(s)\n", added)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func BuildListRecoveryCodesCmd() *cobra.Command {
+	var showSecrets bool
+	cmd := &cobra.Command{
+		Use:     "list <username>",
+		Aliases: []string{"ls"},
+		Short:   "list stored recovery codes for an account",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			codes, err := auth.ListRecoveryCodes(args[0])
+			if err != nil {
+				return err
+			}
+			w := table.NewWriter()
+			w.SetStyle(table.StyleColoredDark)
+			w.AppendHeader(table.Row{"CODE", "STATUS"})
+			for _, code := range codes {
+				value := "(secret)"
+				if showSecrets {
+					value = code.Code
+				}
+				status := "unused"
+				if code.Used() {
+					status = "used " + code.UsedAt.Local().Format("2006-01-02 15:04")
+				}
+				w.AppendRow(table.Row{value, status})
+			}
+			cmd.Println(w.Render())
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&showSecrets, "show-secrets", false, "show recovery codes")
+
+	return cmd
+}
+
+func BuildUseRecoveryCodeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "use <username>",
+		Short: "print the next unused recovery code and mark it as used",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			code, err := auth.UseRecoveryCode(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Your recovery code is: %s\n", code)
+			codes, err := auth.ListRecoveryCodes(args[0])
+			if err != nil {
+				return err
+			}
+			var remaining int
+			for _, c := range codes {
+				if !c.Used() {
+					remaining++
+				}
+			}
+			fmt.Printf("%d unused recovery code(s) remaining\n", remaining)
+			return nil
+		},
+	}
+
+	return cmd
+}
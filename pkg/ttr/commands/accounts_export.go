@@ -0,0 +1,247 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/kralicky/ttr/pkg/auth"
+	"github.com/kralicky/ttr/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// ExportCmd represents the export command
+func BuildExportCmd() *cobra.Command {
+	var armored bool
+	cmd := &cobra.Command{
+		Use:   "export <file> [account...]",
+		Short: "Export accounts and their secrets to an encrypted file",
+		Long: `Export accounts and their secrets to an encrypted file.
+
+The file contains the stored password, two-factor auth secret and recovery
+codes of each account, encrypted with a passphrase. If no accounts are given,
+all accounts are exported. Use "-" as the file name to write to stdout.
+
+The passphrase can be set with the ` + auth.BundlePassphraseEnvVar + ` environment
+variable instead of being prompted for.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			accounts := args[1:]
+			if len(accounts) == 0 {
+				accounts = config.ListAccounts()
+			}
+			for _, account := range accounts {
+				if !config.AccountExists(account) {
+					return fmt.Errorf("account %s does not exist", account)
+				}
+			}
+			if len(accounts) == 0 {
+				return errors.New("no accounts to export")
+			}
+
+			if args[0] != "-" {
+				if _, err := os.Stat(args[0]); err == nil {
+					return fmt.Errorf("%s already exists", args[0])
+				}
+			}
+
+			passphrase, err := promptNewPassphrase()
+			if err != nil {
+				return err
+			}
+
+			bundle, err := auth.NewBundle(accounts)
+			if err != nil {
+				return err
+			}
+
+			if args[0] == "-" {
+				err = bundle.Encrypt(os.Stdout, passphrase, armored)
+			} else {
+				err = writeBundleFile(args[0], bundle, passphrase, armored)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to write bundle: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Exported %d account(s)\n", len(accounts))
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&armored, "armor", "a", false, "write a PEM-encoded (text) file instead of a binary one")
+	return cmd
+}
+
+const (
+	conflictPrompt    = "prompt"
+	conflictSkip      = "skip"
+	conflictOverwrite = "overwrite"
+)
+
+// ImportCmd represents the import command
+func BuildImportCmd() *cobra.Command {
+	var onConflict string
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import accounts and their secrets from an encrypted file",
+		Long: `Import accounts and their secrets from an encrypted file created with
+"ttr accounts export". Use "-" as the file name to read from stdin.
+
+The passphrase can be set with the ` + auth.BundlePassphraseEnvVar + ` environment
+variable instead of being prompted for. When reading from stdin, prompts are
+read from the terminal; without one, --on-conflict must be given if any of
+the accounts already exist.
+
+Overwriting an account replaces all of its stored secrets, including ones
+that are not in the file.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch onConflict {
+			case conflictPrompt, conflictSkip, conflictOverwrite:
+			default:
+				return fmt.Errorf("invalid value for --on-conflict: %q", onConflict)
+			}
+
+			var r io.Reader = os.Stdin
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				r = f
+			}
+
+			// the bundle is read from stdin, so prompts must use the terminal
+			var stdio []survey.AskOpt
+			var noTTY bool
+			if args[0] == "-" {
+				tty, err := os.Open("/dev/tty")
+				if err != nil {
+					noTTY = true
+				} else {
+					defer tty.Close()
+					stdio = append(stdio, survey.WithStdio(tty, os.Stderr, os.Stderr))
+				}
+			}
+
+			passphrase, err := promptPassphrase(stdio, noTTY)
+			if err != nil {
+				return err
+			}
+
+			bundle, err := auth.DecryptBundle(r, passphrase)
+			if err != nil {
+				return err
+			}
+
+			exists := func(name string) bool {
+				return config.AccountExists(name) || auth.HasAnySecret(name)
+			}
+			if noTTY && onConflict == conflictPrompt {
+				for _, acct := range bundle.Accounts {
+					if exists(acct.Name) {
+						return fmt.Errorf("account %s already exists; use --on-conflict when reading from stdin without a terminal", acct.Name)
+					}
+				}
+			}
+
+			var imported int
+			for _, acct := range bundle.Accounts {
+				if exists(acct.Name) {
+					overwrite := onConflict == conflictOverwrite
+					if onConflict == conflictPrompt {
+						if err := survey.AskOne(&survey.Confirm{
+							Message: "Account " + acct.Name + " already exists. Overwrite its stored secrets?",
+						}, &overwrite, stdio...); err != nil {
+							return err
+						}
+					}
+					if !overwrite {
+						fmt.Printf("Skipping %s\n", acct.Name)
+						continue
+					}
+				}
+				if err := acct.Restore(); err != nil {
+					return err
+				}
+				if !config.AccountExists(acct.Name) {
+					config.AddAccount(acct.Name)
+					if err := config.Save(); err != nil {
+						return fmt.Errorf("failed to save config: %w", err)
+					}
+				}
+				imported++
+				fmt.Printf("Imported %s\n", acct.Name)
+			}
+			fmt.Printf("Imported %d of %d account(s)\n", imported, len(bundle.Accounts))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&onConflict, "on-conflict", conflictPrompt, "what to do when an account already exists (prompt, skip, overwrite)")
+	return cmd
+}
+
+// writeBundleFile writes the encrypted bundle to a new file. The bundle is
+// written to a temporary file first, so that a failed export doesn't leave a
+// partial file behind.
+func writeBundleFile(path string, bundle *auth.Bundle, passphrase string, armored bool) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".ttr-export-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := bundle.Encrypt(f, passphrase, armored); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// promptPassphrase reads the bundle passphrase from the environment, or
+// prompts for it with the given stdio options. noTTY is set if stdin is in use
+// and there is no terminal to prompt on.
+func promptPassphrase(stdio []survey.AskOpt, noTTY bool) (string, error) {
+	if pass, ok := os.LookupEnv(auth.BundlePassphraseEnvVar); ok {
+		return pass, nil
+	}
+	if noTTY {
+		return "", fmt.Errorf("no terminal to read the passphrase from; set %s when reading from stdin", auth.BundlePassphraseEnvVar)
+	}
+	var passphrase string
+	if err := survey.AskOne(&survey.Password{
+		Message: "Passphrase:",
+	}, &passphrase, append(stdio, survey.WithValidator(survey.Required))...); err != nil {
+		return "", err
+	}
+	return passphrase, nil
+}
+
+func promptNewPassphrase() (string, error) {
+	if pass, ok := os.LookupEnv(auth.BundlePassphraseEnvVar); ok {
+		return pass, nil
+	}
+	// the bundle may be written to stdout, so prompt on stderr
+	stdio := survey.WithStdio(os.Stdin, os.Stderr, os.Stderr)
+	var passphrase, confirm string
+	if err := survey.AskOne(&survey.Password{
+		Message: "Passphrase:",
+		Help:    "The passphrase will be needed to import the file. It cannot be recovered if lost.",
+	}, &passphrase, survey.WithValidator(survey.Required), stdio); err != nil {
+		return "", err
+	}
+	if err := survey.AskOne(&survey.Password{
+		Message: "Confirm passphrase:",
+	}, &confirm, stdio); err != nil {
+		return "", err
+	}
+	if passphrase != confirm {
+		return "", errors.New("passphrases do not match")
+	}
+	return passphrase, nil
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/kralicky/ttr/pkg/auth"
 	"github.com/kralicky/ttr/pkg/config"
 	"github.com/spf13/cobra"
-	"github.com/zalando/go-keyring"
 )
 
 // ListCmd represents the list command
@@ -31,7 +30,7 @@ func BuildListCmd() *cobra.Command {
 				if showSecrets {
 					pw, err := auth.GetAccountPassword(account)
 					if err != nil {
-						if errors.Is(err, keyring.ErrNotFound) {
+						if errors.Is(err, auth.ErrNotFound) {
 							password = "(not saved)"
 						} else {
 							password = "err: " + err.Error()
@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/kralicky/ttr/pkg/auth"
 	"github.com/kralicky/ttr/pkg/config"
 	"github.com/spf13/cobra"
@@ -10,6 +11,8 @@ import (
 
 // RmCmd represents the rm command
 func BuildRmCmd() *cobra.Command {
+	var keepSecrets bool
+	var yes bool
 	cmd := &cobra.Command{
 		Use:     "rm <username>",
 		Aliases: []string{"remove", "delete"},
@@ -19,8 +22,26 @@ func BuildRmCmd() *cobra.Command {
 			if !config.AccountExists(args[0]) {
 				return fmt.Errorf("account %s does not exist", args[0])
 			}
-			if err := auth.DeleteAccountPassword(args[0]); err != nil {
-				return fmt.Errorf("failed to delete credentials: %w", err)
+			if !yes {
+				msg := "Remove account " + args[0] + " and all of its stored secrets?"
+				if keepSecrets {
+					msg = "Remove account " + args[0] + "? (stored secrets will be kept)"
+				}
+				var confirm bool
+				if err := survey.AskOne(&survey.Confirm{
+					Message: msg,
+				}, &confirm); err != nil {
+					return err
+				}
+				if !confirm {
+					fmt.Println("Aborting.")
+					return nil
+				}
+			}
+			if !keepSecrets {
+				if err := auth.DeleteAllSecrets(args[0]); err != nil {
+					return fmt.Errorf("failed to delete credentials: %w", err)
+				}
 			}
 			config.DeleteAccount(args[0])
 			if err := config.Save(); err != nil {
@@ -29,5 +50,63 @@ func BuildRmCmd() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&keepSecrets, "keep-secrets", false, "keep the stored password, two-factor auth secret and recovery codes")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "do not prompt for confirmation")
+	return cmd
+}
+
+// PruneCmd represents the prune command
+func BuildPruneCmd() *cobra.Command {
+	var yes bool
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Args:  cobra.NoArgs,
+		Short: "Remove stored secrets that do not belong to any account",
+		Long: `Remove stored secrets that do not belong to any account.
+
+Secrets are orphaned when an account is removed with --keep-secrets, or when
+the config file is edited by hand. Only secrets saved by this version of the
+CLI or newer can be found, since the secret store cannot be searched.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names, err := auth.ListSecretAccounts()
+			if err != nil {
+				return fmt.Errorf("failed to list stored secrets: %w", err)
+			}
+			var orphaned []string
+			for _, name := range names {
+				if !config.AccountExists(name) {
+					orphaned = append(orphaned, name)
+				}
+			}
+			if len(orphaned) == 0 {
+				fmt.Println("Nothing to prune.")
+				return nil
+			}
+			if !yes {
+				fmt.Println("The following accounts have stored secrets but no matching config entry:")
+				for _, name := range orphaned {
+					fmt.Println("  " + name)
+				}
+				var confirm bool
+				if err := survey.AskOne(&survey.Confirm{
+					Message: "Delete their stored secrets?",
+				}, &confirm); err != nil {
+					return err
+				}
+				if !confirm {
+					fmt.Println("Aborting.")
+					return nil
+				}
+			}
+			for _, name := range orphaned {
+				if err := auth.DeleteAllSecrets(name); err != nil {
+					return fmt.Errorf("failed to delete secrets for %s: %w", name, err)
+				}
+				fmt.Printf("Deleted secrets for %s\n", name)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "do not prompt for confirmation")
 	return cmd
 }
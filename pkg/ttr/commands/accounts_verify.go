@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/kralicky/ttr/pkg/api"
+	"github.com/kralicky/ttr/pkg/auth"
+	"github.com/kralicky/ttr/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// VerifyCmd represents the verify command
+func BuildVerifyCmd() *cobra.Command {
+	var noPrompt bool
+	cmd := &cobra.Command{
+		Use:   "verify [account...]",
+		Short: "Check that stored credentials can log in",
+		Long: `Check that stored credentials can log in.
+
+Runs the login flow for each account (or all accounts, if none are given) up
+to the point where the game would be launched, and reports whether the
+password, two-factor auth, and login queue stages succeeded. The game is not
+launched, and the resulting session is discarded.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			accounts := args
+			if len(accounts) == 0 {
+				accounts = config.ListAccounts()
+			}
+			for _, account := range accounts {
+				if !config.AccountExists(account) {
+					return fmt.Errorf("account %s does not exist", account)
+				}
+			}
+
+			client := api.NewClient()
+			w := table.NewWriter()
+			w.SetStyle(table.StyleColoredDark)
+			w.AppendHeader(table.Row{"ACCOUNT", "PASSWORD", "2FA", "QUEUE", "RESULT"})
+
+			var failed int
+			for _, account := range accounts {
+				stages := map[auth.LoginStage]string{
+					auth.StagePassword:  "-",
+					auth.StageTwoFactor: "not required",
+					auth.StageQueue:     "not queued",
+				}
+				_, err := auth.Login(cmd.Context(), client, account, auth.LoginOptions{
+					Interactive: !noPrompt,
+					OnStage: func(stage auth.LoginStage, err error) {
+						if err != nil {
+							stages[stage] = text.Colors{text.FgRed}.Sprint("failed")
+						} else {
+							stages[stage] = text.Colors{text.FgGreen}.Sprint("ok")
+						}
+					},
+				})
+				result := text.Colors{text.FgGreen}.Sprint("ok")
+				if err != nil {
+					failed++
+					result = text.Colors{text.FgRed}.Sprint(err.Error())
+				}
+				w.AppendRow(table.Row{
+					account,
+					stages[auth.StagePassword],
+					stages[auth.StageTwoFactor],
+					stages[auth.StageQueue],
+					result,
+				})
+			}
+
+			cmd.Println(w.Render())
+			if failed > 0 {
+				return fmt.Errorf("%d of %d account(s) failed verification", failed, len(accounts))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&noPrompt, "no-prompt", false, "fail instead of prompting for passwords or codes that are not stored")
+	return cmd
+}
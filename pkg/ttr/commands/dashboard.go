@@ -0,0 +1,195 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/kralicky/ttr/pkg/api"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/term"
+)
+
+// Dashboard combines the public game information endpoints. Sections that
+// could not be fetched are nil, and the error is recorded in Errors.
+type Dashboard struct {
+	Status       *api.StatusSpec        `json:"status,omitempty"`
+	Invasions    []invasionRow          `json:"invasions,omitempty"`
+	Districts    []api.District         `json:"districts,omitempty"`
+	FieldOffices []api.ZonedFieldOffice `json:"fieldOffices,omitempty"`
+	SillyMeter   *api.SillyMeterSpec    `json:"sillyMeter,omitempty"`
+	Errors       map[string]string      `json:"errors,omitempty"`
+}
+
+func fetchDashboard(ctx context.Context, client api.InfoClient) *Dashboard {
+	d := &Dashboard{}
+	errs := make([]error, 5)
+	var eg errgroup.Group
+	eg.Go(func() error {
+		status, err := client.Status(ctx)
+		if err == nil {
+			d.Status = &status
+		}
+		errs[0] = err
+		return nil
+	})
+	eg.Go(func() error {
+		spec, err := client.Invasions(ctx)
+		if err == nil {
+			d.Invasions = invasionRows(spec)
+			slices.SortFunc(d.Invasions, func(a, b invasionRow) int {
+				return strings.Compare(a.District, b.District)
+			})
+		}
+		errs[1] = err
+		return nil
+	})
+	eg.Go(func() error {
+		pop, err := client.Population(ctx)
+		if err == nil {
+			d.Districts = api.Districts(pop, nil)
+		}
+		errs[2] = err
+		return nil
+	})
+	eg.Go(func() error {
+		spec, err := client.FieldOffices(ctx)
+		if err == nil {
+			d.FieldOffices = spec.List()
+		}
+		errs[3] = err
+		return nil
+	})
+	eg.Go(func() error {
+		spec, err := client.SillyMeter(ctx)
+		if err == nil {
+			d.SillyMeter = spec
+		}
+		errs[4] = err
+		return nil
+	})
+	eg.Wait()
+
+	for i, section := range []string{"status", "invasions", "population", "fieldOffices", "sillyMeter"} {
+		if errs[i] != nil {
+			if d.Errors == nil {
+				d.Errors = map[string]string{}
+			}
+			d.Errors[section] = errs[i].Error()
+		}
+	}
+	return d
+}
+
+func BuildDashboardCmd() *cobra.Command {
+	var output string
+	var once bool
+	var interval time.Duration
+	cmd := &cobra.Command{
+		Use:   "dashboard",
+		Short: "Show game status, invasions, population, field offices and the Silly Meter",
+		Long: `Show game status, invasions, population, field offices and the Silly Meter.
+
+When run in a terminal, a full-screen dashboard is shown, which refreshes
+periodically and can launch and stop accounts. Accounts launched from the
+dashboard must have a stored password (and two-factor auth secret, if
+required). With --once, or when the output is not a terminal, the information
+is printed once instead.`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if interval < time.Second {
+				return errors.New("--interval must be at least 1s")
+			}
+			return validateOutputFormat(output)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := api.NewClient()
+			if !once && output == outputText && term.IsTerminal(int(os.Stdout.Fd())) {
+				return runDashboardTUI(cmd.Context(), client, interval)
+			}
+			d := fetchDashboard(cmd.Context(), client)
+			return writeOutput(cmd.OutOrStdout(), output, d, func() {
+				printDashboard(cmd, d)
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "output format (text, json, yaml)")
+	cmd.Flags().BoolVar(&once, "once", false, "print the information once instead of showing the full-screen dashboard")
+	cmd.Flags().DurationVar(&interval, "interval", 30*time.Second, "how often to refresh the full-screen dashboard")
+	return cmd
+}
+
+func printDashboard(cmd *cobra.Command, d *Dashboard) {
+	heading := func(s string) {
+		cmd.Println(text.Colors{text.Bold, text.Underline}.Sprint(s))
+	}
+	sectionError := func(section string) bool {
+		if err, ok := d.Errors[section]; ok {
+			cmd.Println(text.Colors{text.FgRed}.Sprint("error: ", err))
+			cmd.Println()
+			return true
+		}
+		return false
+	}
+
+	heading("Status")
+	if !sectionError("status") {
+		printStatus(cmd, *d.Status)
+		cmd.Println()
+	}
+
+	heading("Population")
+	if !sectionError("population") {
+		var total int
+		var parts []string
+		for _, district := range d.Districts {
+			total += district.Population
+			if !district.Online() {
+				parts = append(parts, text.Colors{text.FgRed}.Sprintf("%s (%s)", district.Name, district.Status))
+			}
+		}
+		cmd.Printf("%d toons in %d districts\n", total, len(d.Districts))
+		if len(parts) > 0 {
+			cmd.Println("Offline:", strings.Join(parts, ", "))
+		}
+		cmd.Println()
+	}
+
+	heading("Invasions")
+	if !sectionError("invasions") {
+		if len(d.Invasions) == 0 {
+			cmd.Println("No invasions.")
+		}
+		for _, inv := range d.Invasions {
+			cmd.Printf("%-16s %s (%d left)\n", inv.District, inv.Cog, inv.Remaining)
+		}
+		cmd.Println()
+	}
+
+	heading("Field Offices")
+	if !sectionError("fieldOffices") {
+		if len(d.FieldOffices) == 0 {
+			cmd.Println("No field offices.")
+		} else {
+			w := table.NewWriter()
+			w.SetStyle(table.StyleLight)
+			for _, fo := range d.FieldOffices {
+				w.AppendRow(table.Row{fieldOfficeStreet(fo), stars(fo.Stars()), fmt.Sprintf("%d annexes", fo.Annexes), fieldOfficeStatus(fo)})
+			}
+			cmd.Println(w.Render())
+		}
+		cmd.Println()
+	}
+
+	heading("Silly Meter")
+	if !sectionError("sillyMeter") {
+		printSillyMeter(cmd, d.SillyMeter)
+	}
+}
@@ -0,0 +1,540 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kralicky/ttr/pkg/api"
+	"github.com/kralicky/ttr/pkg/auth"
+	"github.com/kralicky/ttr/pkg/config"
+	"github.com/kralicky/ttr/pkg/game"
+	"github.com/mattn/go-runewidth"
+	log "github.com/sirupsen/logrus"
+)
+
+// toon is the state of an account in the dashboard's process table.
+type toon struct {
+	state string
+	proc  *game.Process
+	// set when the process was killed from the dashboard, so that the exit
+	// error is not reported
+	killed bool
+}
+
+type dashboardTUI struct {
+	ctx          context.Context
+	client       api.Client
+	screen       tcell.Screen
+	interval     time.Duration
+	refresh      chan struct{}
+	integrations *integrations
+	// Options used to start game processes.
+	procOpts []game.ProcessOption
+
+	mu        sync.Mutex
+	data      *Dashboard
+	fetchedAt time.Time
+	accounts  []string
+	toons     map[string]*toon
+	selected  int
+	message   string
+	quitting  bool
+
+	// held while syncing game data, so that it is only done once even if
+	// several accounts are launched at the same time
+	syncMu sync.Mutex
+	synced bool
+}
+
+func runDashboardTUI(ctx context.Context, client api.Client, interval time.Duration) error {
+	// prompts can't be shown once the screen is set up, so the vault
+	// passphrase (if any) is asked for now
+	if err := auth.UnlockStore(); err != nil {
+		return err
+	}
+	// set up before the screen, so that warnings can be seen
+	integrations, err := setupIntegrations()
+	if err != nil {
+		return err
+	}
+	defer integrations.Close()
+	procOpts := slices.Clone(integrations.procOpts)
+
+	mapRenderer, stopRenderer, err := setupMapRenderer()
+	if err != nil {
+		return err
+	}
+	defer stopRenderer()
+	switch mapRenderer.(type) {
+	case game.TerminalRenderer, game.TextRenderer:
+		// these would draw over the screen
+		log.Warnf("maps.renderer %q can't be used with the dashboard; maps will not be shown", config.MapRenderer())
+	default:
+		procOpts = append(procOpts, game.WithMapRenderer(mapRenderer))
+	}
+
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+	if err := screen.Init(); err != nil {
+		return err
+	}
+	defer screen.Fini()
+
+	// log output would draw over the screen
+	prevOut := log.StandardLogger().Out
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(prevOut)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	t := &dashboardTUI{
+		integrations: integrations,
+		procOpts:     procOpts,
+		ctx:          ctx,
+		client:       client,
+		screen:       screen,
+		interval:     interval,
+		refresh:      make(chan struct{}, 1),
+		accounts:     config.ListAccounts(),
+		toons:        map[string]*toon{},
+	}
+	go t.refreshLoop()
+	go func() {
+		// redraw every second to keep timers up to date
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				t.redraw()
+			}
+		}
+	}()
+
+	for {
+		t.draw()
+		switch ev := screen.PollEvent().(type) {
+		case nil:
+			return nil
+		case *tcell.EventResize:
+			screen.Sync()
+		case *tcell.EventKey:
+			if t.handleKey(ev) {
+				t.stopAll()
+				return nil
+			}
+		}
+	}
+}
+
+// redraw asks the event loop to draw the screen again.
+func (t *dashboardTUI) redraw() {
+	t.screen.PostEvent(tcell.NewEventInterrupt(nil))
+}
+
+func (t *dashboardTUI) refreshLoop() {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		d := fetchDashboard(t.ctx, t.client)
+		t.mu.Lock()
+		t.data = d
+		t.fetchedAt = time.Now()
+		t.mu.Unlock()
+		t.redraw()
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-t.refresh:
+		}
+	}
+}
+
+// handleKey handles a key press, and returns true if the dashboard should exit.
+func (t *dashboardTUI) handleKey(ev *tcell.EventKey) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	quitting := t.quitting
+	t.quitting = false
+	t.message = ""
+
+	switch {
+	case ev.Key() == tcell.KeyUp:
+		t.selected = max(t.selected-1, 0)
+	case ev.Key() == tcell.KeyDown:
+		t.selected = min(t.selected+1, max(len(t.accounts)-1, 0))
+	case ev.Key() == tcell.KeyEnter, ev.Rune() == 'l':
+		if account, ok := t.selectedAccount(); ok {
+			t.launch(account)
+		}
+	case ev.Rune() == 'x':
+		if account, ok := t.selectedAccount(); ok {
+			t.kill(account)
+		}
+	case ev.Rune() == 'r':
+		select {
+		case t.refresh <- struct{}{}:
+		default:
+		}
+		t.message = "Refreshing..."
+	case ev.Key() == tcell.KeyEscape, ev.Key() == tcell.KeyCtrlC, ev.Rune() == 'q':
+		running := t.runningCount()
+		if running == 0 || quitting {
+			return true
+		}
+		t.quitting = true
+		t.message = fmt.Sprintf("%d toon(s) running will be closed; press q again to quit", running)
+	}
+	return false
+}
+
+func (t *dashboardTUI) selectedAccount() (string, bool) {
+	if t.selected >= len(t.accounts) {
+		return "", false
+	}
+	return t.accounts[t.selected], true
+}
+
+func (t *dashboardTUI) runningCount() int {
+	var n int
+	for _, s := range t.toons {
+		if s.proc != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// setState updates the state of an account from a background goroutine.
+func (t *dashboardTUI) setState(account string, fn func(s *toon)) {
+	t.mu.Lock()
+	fn(t.toons[account])
+	t.mu.Unlock()
+	t.redraw()
+}
+
+// launch logs in and starts the game for an account. t.mu must be held.
+func (t *dashboardTUI) launch(account string) {
+	if s, ok := t.toons[account]; ok && (s.proc != nil || s.state == "logging in" || s.state == "updating") {
+		t.message = account + " is already running"
+		return
+	}
+	t.toons[account] = &toon{state: "logging in"}
+
+	go func() {
+		creds, err := auth.Login(t.ctx, t.client, account, auth.LoginOptions{
+			// prompts can't be shown while the dashboard is running
+			Interactive: false,
+			Output:      io.Discard,
+		})
+		t.integrations.loggedIn(account, err)
+		if err != nil {
+			if errors.Is(err, auth.ErrNoStoredPassword) || errors.Is(err, auth.ErrNoStoredTwoFactorSecret) {
+				err = fmt.Errorf("%w (use ttr launch to log in interactively)", err)
+			}
+			t.setState(account, func(s *toon) { s.state = "error: " + err.Error() })
+			return
+		}
+
+		t.setState(account, func(s *toon) { s.state = "updating" })
+		if err := t.syncGameData(); err != nil {
+			t.setState(account, func(s *toon) { s.state = "update failed: " + err.Error() })
+			return
+		}
+
+		proc, err := game.StartProcess(t.ctx, account, creds, t.procOpts...)
+		if err != nil {
+			t.setState(account, func(s *toon) { s.state = "error: " + err.Error() })
+			return
+		}
+		t.setState(account, func(s *toon) {
+			s.state = "running"
+			s.proc = proc
+		})
+
+		<-proc.Done()
+		t.setState(account, func(s *toon) {
+			s.proc = nil
+			if err := proc.Err(); err != nil && !s.killed {
+				s.state = "exited: " + err.Error()
+			} else {
+				s.state = "exited"
+			}
+		})
+	}()
+}
+
+func (t *dashboardTUI) syncGameData() error {
+	t.syncMu.Lock()
+	defer t.syncMu.Unlock()
+	if t.synced {
+		return nil
+	}
+	if _, err := game.SyncGameData(t.ctx, t.client); err != nil {
+		return err
+	}
+	t.synced = true
+	return nil
+}
+
+// kill stops the game for an account. t.mu must be held.
+func (t *dashboardTUI) kill(account string) {
+	s, ok := t.toons[account]
+	if !ok || s.proc == nil {
+		t.message = account + " is not running"
+		return
+	}
+	s.killed = true
+	if err := s.proc.Kill(); err != nil {
+		t.message = fmt.Sprintf("failed to stop %s: %v", account, err)
+		return
+	}
+	s.state = "stopping"
+}
+
+// stopAll stops all running games, and waits a few seconds for them to exit
+// before they are killed when the dashboard's context is canceled.
+func (t *dashboardTUI) stopAll() {
+	t.mu.Lock()
+	var procs []*game.Process
+	for account, s := range t.toons {
+		if s.proc != nil {
+			procs = append(procs, s.proc)
+			t.kill(account)
+		}
+	}
+	t.mu.Unlock()
+
+	timeout := time.After(5 * time.Second)
+	for _, p := range procs {
+		select {
+		case <-p.Done():
+		case <-timeout:
+			return
+		}
+	}
+}
+
+func zoneLabel(req *game.EnterRequestStatus) string {
+	if req == nil {
+		return ""
+	}
+	name := game.LocationName(*req)
+	if req.Where == "" || req.Where == name {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, req.Where)
+}
+
+var (
+	styleDefault = tcell.StyleDefault
+	styleBold    = styleDefault.Bold(true)
+	styleHeading = styleDefault.Bold(true).Underline(true)
+	styleDim     = styleDefault.Dim(true)
+	styleGreen   = styleDefault.Foreground(tcell.ColorGreen)
+	styleRed     = styleDefault.Foreground(tcell.ColorRed)
+	styleYellow  = styleDefault.Foreground(tcell.ColorYellow)
+)
+
+// print draws s at (x, y), clipped to maxX, and returns the x position after
+// the last cell drawn.
+func (t *dashboardTUI) print(x, y, maxX int, style tcell.Style, s string) int {
+	for _, r := range s {
+		w := runewidth.RuneWidth(r)
+		if x+w > maxX {
+			break
+		}
+		t.screen.SetContent(x, y, r, nil, style)
+		x += w
+	}
+	return x
+}
+
+func (t *dashboardTUI) draw() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.screen.Clear()
+	width, height := t.screen.Size()
+	d := t.data
+
+	t.print(1, 0, width, styleBold, "ttr dashboard")
+	if !t.fetchedAt.IsZero() {
+		updated := fmt.Sprintf("updated %s ago", time.Since(t.fetchedAt).Truncate(time.Second))
+		t.print(width-len(updated)-1, 0, width, styleDim, updated)
+	}
+	if d == nil {
+		t.print(1, 2, width, styleDefault, "Loading...")
+		t.screen.Show()
+		return
+	}
+
+	// game status and banner
+	if err, ok := d.Errors["status"]; ok {
+		t.print(1, 1, width, styleRed, "error: "+err)
+	} else if d.Status != nil {
+		x := 1
+		if d.Status.Open {
+			x = t.print(x, 1, width, styleGreen, "● Game is open")
+		} else {
+			x = t.print(x, 1, width, styleRed, "● Game is closed")
+		}
+		if d.Status.Banner != "" {
+			t.print(x+2, 1, width, styleYellow, d.Status.Banner)
+		}
+	}
+
+	// the process table and footer are anchored to the bottom of the screen,
+	// and the info columns get the remaining space
+	tableTop := max(height-len(t.accounts)-5, 3)
+	columnsHeight := max(tableTop-6, 0)
+
+	colWidth := max((width-2)/3, 10)
+	columns := []struct {
+		title   string
+		section string
+		rows    func() []styledLine
+	}{
+		{"Invasions", "invasions", d.invasionLines},
+		{"Districts", "population", d.districtLines},
+		{"Field Offices", "fieldOffices", d.fieldOfficeLines},
+	}
+	for i, col := range columns {
+		x := 1 + i*colWidth
+		maxX := min(x+colWidth-1, width)
+		t.print(x, 3, maxX, styleHeading, col.title)
+		if err, ok := d.Errors[col.section]; ok {
+			t.print(x, 4, maxX, styleRed, "error: "+err)
+			continue
+		}
+		lines := col.rows()
+		for j, line := range lines {
+			if j >= columnsHeight {
+				break
+			}
+			if j == columnsHeight-1 && len(lines) > columnsHeight {
+				t.print(x, 4+j, maxX, styleDim, fmt.Sprintf("(%d more)", len(lines)-j))
+				break
+			}
+			t.print(x, 4+j, maxX, line.style, line.text)
+		}
+	}
+
+	// silly meter
+	sillyY := tableTop - 2
+	x := t.print(1, sillyY, width, styleBold, "Silly Meter: ")
+	if err, ok := d.Errors["sillyMeter"]; ok {
+		t.print(x, sillyY, width, styleRed, "error: "+err)
+	} else if s := d.SillyMeter; s != nil {
+		switch s.State {
+		case api.SillyMeterActive:
+			t.print(x, sillyY, width, styleDefault, fmt.Sprintf("%s %.1f%%", meterBar(s.Percent(), 20), s.Percent()))
+		case api.SillyMeterReward:
+			name, _, _ := s.ActiveReward()
+			t.print(x, sillyY, width, styleGreen, fmt.Sprintf("%s, ends %s", name, untilString(s.NextUpdate())))
+		default:
+			t.print(x, sillyY, width, styleDefault, fmt.Sprintf("cooling down, starts filling %s", untilString(s.NextUpdate())))
+		}
+	}
+
+	t.drawProcessTable(tableTop, width)
+
+	t.print(1, height-2, width, styleYellow, t.message)
+	t.print(1, height-1, width, styleDim, "↑/↓ select  enter/l launch  x kill  r refresh  q quit")
+	t.screen.Show()
+}
+
+func (t *dashboardTUI) drawProcessTable(y, width int) {
+	cols := []int{1, 22, 44, 52, 62}
+	for i, h := range []string{"ACCOUNT", "STATE", "PID", "UPTIME", "ZONE"} {
+		t.print(cols[i], y, width, styleHeading, h)
+	}
+	if len(t.accounts) == 0 {
+		t.print(1, y+1, width, styleDim, "No accounts found, run `ttr accounts add` to add one.")
+		return
+	}
+	for i, account := range t.accounts {
+		row := y + 1 + i
+		style := styleDefault
+		if i == t.selected {
+			style = style.Reverse(true)
+			for x := 0; x < width; x++ {
+				t.screen.SetContent(x, row, ' ', nil, style)
+			}
+		}
+		fields := []string{account, "", "", "", ""}
+		if s, ok := t.toons[account]; ok {
+			fields[1] = s.state
+			if s.proc != nil {
+				fields[2] = fmt.Sprint(s.proc.Pid())
+				fields[3] = time.Since(s.proc.Started).Truncate(time.Second).String()
+				fields[4] = zoneLabel(s.proc.Zone())
+			}
+		}
+		for j, f := range fields {
+			maxX := width
+			if j < len(cols)-1 {
+				maxX = cols[j+1] - 1
+			}
+			t.print(cols[j], row, maxX, style, f)
+		}
+	}
+}
+
+type styledLine struct {
+	text  string
+	style tcell.Style
+}
+
+func (d *Dashboard) invasionLines() []styledLine {
+	if len(d.Invasions) == 0 {
+		return []styledLine{{"No invasions.", styleDim}}
+	}
+	lines := make([]styledLine, 0, len(d.Invasions))
+	for _, inv := range d.Invasions {
+		lines = append(lines, styledLine{fmt.Sprintf("%-15s %s (%d)", inv.District, inv.Cog, inv.Remaining), styleDefault})
+	}
+	return lines
+}
+
+func (d *Dashboard) districtLines() []styledLine {
+	lines := make([]styledLine, 0, len(d.Districts))
+	for _, district := range d.Districts {
+		line := styledLine{fmt.Sprintf("%-15s %4d", district.Name, district.Population), styleDefault}
+		if !district.Online() {
+			line.text += " " + district.Status
+			line.style = styleRed
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (d *Dashboard) fieldOfficeLines() []styledLine {
+	if len(d.FieldOffices) == 0 {
+		return []styledLine{{"No field offices.", styleDim}}
+	}
+	lines := make([]styledLine, 0, len(d.FieldOffices))
+	for _, fo := range d.FieldOffices {
+		line := styledLine{fmt.Sprintf("%-18s %-3s %2d", fieldOfficeStreet(fo), strings.Repeat("★", fo.Stars()), fo.Annexes), styleDefault}
+		if _, expiring := fo.ExpiresAt(); expiring || !fo.Open {
+			line.style = styleDim
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
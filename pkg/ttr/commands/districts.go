@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/kralicky/ttr/pkg/api"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// fetchDistricts fetches population and invasion data in parallel. Invasion
+// data is optional; if it cannot be fetched, districts are returned without it.
+func fetchDistricts(ctx context.Context, client api.InfoClient) (*api.PopulationSpec, []api.District, error) {
+	var pop *api.PopulationSpec
+	var inv *api.InvasionsSpec
+	var eg errgroup.Group
+	eg.Go(func() error {
+		var err error
+		pop, err = client.Population(ctx)
+		return err
+	})
+	eg.Go(func() error {
+		inv, _ = client.Invasions(ctx)
+		return nil
+	})
+	if err := eg.Wait(); err != nil {
+		return nil, nil, err
+	}
+	return pop, api.Districts(pop, inv), nil
+}
+
+func BuildDistrictsCmd() *cobra.Command {
+	var output string
+	var sortBy string
+	cmd := &cobra.Command{
+		Use:   "districts",
+		Short: "List districts with their population and invasions",
+		Args:  cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(output); err != nil {
+				return err
+			}
+			switch sortBy {
+			case "population", "name":
+			default:
+				return fmt.Errorf("invalid value for --sort: %q (expected population or name)", sortBy)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := api.NewClient()
+			pop, districts, err := fetchDistricts(cmd.Context(), client)
+			if err != nil {
+				return err
+			}
+			if sortBy == "population" {
+				slices.SortStableFunc(districts, func(a, b api.District) int {
+					return a.Population - b.Population
+				})
+			}
+
+			return writeOutput(cmd.OutOrStdout(), output, districts, func() {
+				w := table.NewWriter()
+				w.SetStyle(table.StyleColoredDark)
+				w.AppendHeader(table.Row{"DISTRICT", "POPULATION", "STATUS", "INVASION"})
+				for _, d := range districts {
+					status := text.Colors{text.FgGreen}.Sprint(d.Status)
+					if !d.Online() {
+						status = text.Colors{text.FgRed}.Sprint(d.Status)
+					}
+					var invasion string
+					if d.Invasion != nil {
+						invasion = fmt.Sprintf("%s (%d left)", d.Invasion.CogName(), d.Invasion.Progress.Remaining())
+					}
+					w.AppendRow(table.Row{d.Name, d.Population, status, invasion})
+				}
+				w.AppendFooter(table.Row{"TOTAL", pop.TotalPopulation})
+				cmd.Println(w.Render())
+				if pop.LastUpdated > 0 {
+					cmd.Printf("Last updated %s ago\n", time.Since(time.Unix(pop.LastUpdated, 0)).Truncate(time.Second))
+				}
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "output format (text, json, yaml)")
+	cmd.Flags().StringVar(&sortBy, "sort", "population", "sort by population (least first) or name")
+	return cmd
+}
+
+// printDistrictRecommendation prints the districts that best match the
+// preference. Errors are printed rather than returned, since the
+// recommendation is only informational.
+func printDistrictRecommendation(cmd *cobra.Command, client api.InfoClient, pref api.DistrictPreference) {
+	_, districts, err := fetchDistricts(cmd.Context(), client)
+	if err != nil {
+		cmd.PrintErrln("failed to fetch district population:", err)
+		return
+	}
+	recommended := api.RecommendDistricts(districts, pref)
+	if len(recommended) == 0 {
+		cmd.Println(text.Colors{text.FgYellow}.Sprint("No districts match the preferred district criteria."))
+		return
+	}
+	var names []string
+	for i, d := range recommended {
+		if i == 3 {
+			break
+		}
+		desc := fmt.Sprintf("%s (%d toons", d.Name, d.Population)
+		if d.Invasion != nil {
+			desc += ", " + d.Invasion.CogName() + " invasion"
+		}
+		names = append(names, desc+")")
+	}
+	cmd.Println(text.Colors{text.Bold}.Sprint("Recommended districts: ") + strings.Join(names, ", "))
+}
@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/kralicky/ttr/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+func BuildFieldOfficesCmd() *cobra.Command {
+	var output string
+	var difficulty int
+	var watch bool
+	var interval time.Duration
+	cmd := &cobra.Command{
+		Use:     "fieldoffices",
+		Aliases: []string{"fo"},
+		Short:   "List current field offices",
+		Long: `List current field offices.
+
+With --watch, field offices are polled until interrupted, and an alert is
+printed each time a new field office appears. Use --difficulty to only show (or
+be alerted about) field offices with the given number of stars.`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(output); err != nil {
+				return err
+			}
+			if difficulty < 0 || difficulty > 3 {
+				return errors.New("--difficulty must be between 1 and 3 stars")
+			}
+			if interval < time.Second {
+				return errors.New("--interval must be at least 1s")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := api.NewClient()
+			filter := func(list []api.ZonedFieldOffice) []api.ZonedFieldOffice {
+				return slices.DeleteFunc(list, func(fo api.ZonedFieldOffice) bool {
+					return difficulty != 0 && fo.Stars() != difficulty
+				})
+			}
+			if watch {
+				return watchFieldOffices(cmd.Context(), cmd, client, output, interval, filter)
+			}
+
+			spec, err := client.FieldOffices(cmd.Context())
+			if err != nil {
+				return err
+			}
+			list := filter(spec.List())
+			return writeOutput(cmd.OutOrStdout(), output, list, func() {
+				if len(list) == 0 {
+					cmd.Println("No field offices found.")
+					return
+				}
+				w := table.NewWriter()
+				w.SetStyle(table.StyleColoredDark)
+				w.AppendHeader(table.Row{"STREET", "PLAYGROUND", "DIFFICULTY", "ANNEXES", "STATUS"})
+				for _, fo := range list {
+					w.AppendRow(table.Row{fieldOfficeStreet(fo), fo.Hood, stars(fo.Stars()), fo.Annexes, fieldOfficeStatus(fo)})
+				}
+				cmd.Println(w.Render())
+				if spec.LastUpdated > 0 {
+					cmd.Printf("Last updated %s ago\n", time.Since(time.Unix(spec.LastUpdated, 0)).Truncate(time.Second))
+				}
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "output format (text, json, yaml)")
+	cmd.Flags().IntVar(&difficulty, "difficulty", 0, "only show field offices with this many stars (1-3)")
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "keep polling, and alert when a new field office appears")
+	cmd.Flags().DurationVar(&interval, "interval", 30*time.Second, "how often to poll field offices")
+	return cmd
+}
+
+func stars(n int) string {
+	return strings.Repeat("★", n)
+}
+
+func fieldOfficeStreet(fo api.ZonedFieldOffice) string {
+	if fo.Street == "" {
+		return fmt.Sprintf("Zone %d", fo.ZoneId)
+	}
+	return fo.Street
+}
+
+func fieldOfficeStatus(fo api.ZonedFieldOffice) string {
+	if t, ok := fo.ExpiresAt(); ok {
+		return text.Colors{text.FgYellow}.Sprintf("expiring in %s", time.Until(t).Truncate(time.Second))
+	}
+	if !fo.Open {
+		return text.Colors{text.FgRed}.Sprint("closed")
+	}
+	return text.Colors{text.FgGreen}.Sprint("open")
+}
+
+// FieldOfficeAlert is printed in watch mode when a new field office appears.
+type FieldOfficeAlert struct {
+	Time        time.Time            `json:"time"`
+	FieldOffice api.ZonedFieldOffice `json:"fieldOffice"`
+}
+
+func watchFieldOffices(
+	ctx context.Context,
+	cmd *cobra.Command,
+	client api.InfoClient,
+	output string,
+	interval time.Duration,
+	filter func([]api.ZonedFieldOffice) []api.ZonedFieldOffice,
+) error {
+	seen := map[int64]bool{}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		spec, err := client.FieldOffices(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			cmd.PrintErrln("error fetching field offices:", err)
+		} else {
+			list := filter(spec.List())
+			current := map[int64]bool{}
+			for _, fo := range list {
+				current[fo.ZoneId] = true
+				if seen[fo.ZoneId] {
+					continue
+				}
+				if err := printFieldOfficeAlert(cmd, output, FieldOfficeAlert{
+					Time:        time.Now(),
+					FieldOffice: fo,
+				}); err != nil {
+					return err
+				}
+			}
+			// field offices that have gone away can be alerted about again if
+			// a new one opens in the same zone
+			seen = current
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func printFieldOfficeAlert(cmd *cobra.Command, output string, alert FieldOfficeAlert) error {
+	switch output {
+	case outputJSON:
+		// one object per line, so the output can be consumed as a stream
+		return json.NewEncoder(cmd.OutOrStdout()).Encode(alert)
+	case outputYAML:
+		fmt.Fprintln(cmd.OutOrStdout(), "---")
+		return writeOutput(cmd.OutOrStdout(), output, alert, nil)
+	}
+	fo := alert.FieldOffice
+	where := fieldOfficeStreet(fo)
+	if fo.Hood != "" {
+		where += ", " + fo.Hood
+	}
+	// ring the terminal bell
+	cmd.Print("\a")
+	cmd.Println(alert.Time.Local().Format(time.TimeOnly),
+		text.Colors{text.Bold}.Sprintf("%s field office in %s", stars(fo.Stars()), where),
+		fmt.Sprintf("(%d annexes, %s)", fo.Annexes, fieldOfficeStatus(fo)))
+	return nil
+}
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kralicky/ttr/pkg/config"
+	"github.com/kralicky/ttr/pkg/discord"
+	"github.com/kralicky/ttr/pkg/events"
+	"github.com/kralicky/ttr/pkg/game"
+	"github.com/kralicky/ttr/pkg/notify"
+	log "github.com/sirupsen/logrus"
+)
+
+// sinkDrainTimeout limits how long Close waits for pending notifications.
+const sinkDrainTimeout = 10 * time.Second
+
+// integrations are the optional consumers of game events: desktop
+// notifications, Discord Rich Presence, and event sinks.
+type integrations struct {
+	// Options used to start game processes, which attach the integrations.
+	procOpts  []game.ProcessOption
+	publisher *events.Publisher
+	closers   []func()
+}
+
+// setupIntegrations sets up the integrations enabled in the config. Close
+// must be called once the game processes have exited.
+func setupIntegrations() (*integrations, error) {
+	i := &integrations{}
+
+	if config.NotificationsEnabled() {
+		fields, err := config.NotificationRules()
+		if err != nil {
+			return nil, err
+		}
+		rules, err := notify.ParseRules(fields)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notifications.rules: %w", err)
+		}
+		notifier, err := notify.NewDBusNotifier()
+		if err != nil {
+			// notifications are optional, so don't stop the game from launching
+			log.Warnf("desktop notifications are unavailable: %v", err)
+		} else {
+			sink := notify.NewSink(notifier, rules)
+			i.procOpts = append(i.procOpts, game.WithTrackerHook(sink.Attach))
+			i.closers = append(i.closers, func() {
+				// the game usually exits right after a disconnect, so give
+				// the notification a chance to be sent
+				if !sink.Wait(sinkDrainTimeout) {
+					log.Warn("timed out waiting for notifications to be sent")
+				}
+				notifier.Close()
+			})
+		}
+	}
+
+	if config.DiscordEnabled() {
+		clientId := config.DiscordClientId()
+		if clientId == "" {
+			i.Close()
+			return nil, errors.New("discord.clientId must be set to enable Discord Rich Presence")
+		}
+		presence := discord.NewPresence(discord.PresenceOptions{
+			ClientId:         clientId,
+			DisabledAccounts: config.DiscordDisabledAccounts(),
+			LargeImage:       config.DiscordLargeImage(),
+		})
+		i.procOpts = append(i.procOpts, game.WithTrackerHook(presence.Attach))
+		i.closers = append(i.closers, func() { presence.Close() })
+	}
+
+	if dests := config.EventSinks(); len(dests) > 0 {
+		var sinks []events.Sink
+		for _, dest := range dests {
+			sink, err := events.ParseSink(dest)
+			if err != nil {
+				for _, s := range sinks {
+					s.Close()
+				}
+				i.Close()
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		}
+		i.publisher = events.NewPublisher(sinks...)
+		i.procOpts = append(i.procOpts,
+			game.WithTrackerHook(i.publisher.Attach),
+			game.WithLifecycleHook(i.publisher.PublishProcessEvent),
+		)
+		i.closers = append(i.closers, func() { i.publisher.Close() })
+	}
+
+	return i, nil
+}
+
+// loggedIn publishes a login event, if event sinks are configured. err is the
+// error returned by the login, if any.
+func (i *integrations) loggedIn(account string, err error) {
+	if i.publisher != nil {
+		i.publisher.Publish(events.NewLoginEvent(account, err))
+	}
+}
+
+func (i *integrations) Close() {
+	for _, c := range i.closers {
+		c()
+	}
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/kralicky/ttr/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+type invasionRow struct {
+	District   string         `json:"district"`
+	Cog        string         `json:"cog"`
+	Department api.Department `json:"department"`
+	Defeated   int            `json:"defeated"`
+	Total      int            `json:"total"`
+	Remaining  int            `json:"remaining"`
+	AsOf       time.Time      `json:"asOf"`
+}
+
+func invasionRows(spec *api.InvasionsSpec) []invasionRow {
+	rows := make([]invasionRow, 0, len(spec.Invasions))
+	for district, inv := range spec.Invasions {
+		rows = append(rows, invasionRow{
+			District:   district,
+			Cog:        inv.CogName(),
+			Department: inv.Department(),
+			Defeated:   inv.Progress.Defeated,
+			Total:      inv.Progress.Total,
+			Remaining:  inv.Progress.Remaining(),
+			AsOf:       inv.AsOfTime(),
+		})
+	}
+	return rows
+}
+
+func BuildInvasionsCmd() *cobra.Command {
+	var output string
+	var cog string
+	var department string
+	var sortBy string
+	cmd := &cobra.Command{
+		Use:   "invasions",
+		Short: "List current cog invasions",
+		Args:  cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(output); err != nil {
+				return err
+			}
+			if department != "" {
+				if _, ok := api.ParseDepartment(department); !ok {
+					return fmt.Errorf("unknown department %q", department)
+				}
+			}
+			switch sortBy {
+			case "remaining", "district", "cog":
+			default:
+				return fmt.Errorf("invalid value for --sort: %q (expected remaining, district, or cog)", sortBy)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := api.NewClient()
+			spec, err := client.Invasions(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			rows := invasionRows(spec)
+			dept, _ := api.ParseDepartment(department)
+			rows = slices.DeleteFunc(rows, func(r invasionRow) bool {
+				if cog != "" && !strings.Contains(strings.ToLower(r.Cog), strings.ToLower(cog)) {
+					return true
+				}
+				return department != "" && r.Department != dept
+			})
+			slices.SortFunc(rows, func(a, b invasionRow) int {
+				switch sortBy {
+				case "district":
+					return strings.Compare(a.District, b.District)
+				case "cog":
+					if c := strings.Compare(a.Cog, b.Cog); c != 0 {
+						return c
+					}
+				default:
+					if a.Remaining != b.Remaining {
+						return b.Remaining - a.Remaining
+					}
+				}
+				return strings.Compare(a.District, b.District)
+			})
+
+			return writeOutput(cmd.OutOrStdout(), output, rows, func() {
+				if len(rows) == 0 {
+					cmd.Println("No invasions found.")
+					return
+				}
+				w := table.NewWriter()
+				w.SetStyle(table.StyleColoredDark)
+				w.AppendHeader(table.Row{"DISTRICT", "COG", "DEPARTMENT", "PROGRESS", "REMAINING"})
+				for _, r := range rows {
+					w.AppendRow(table.Row{r.District, r.Cog, r.Department, fmt.Sprintf("%d/%d", r.Defeated, r.Total), r.Remaining})
+				}
+				cmd.Println(w.Render())
+				if spec.LastUpdated > 0 {
+					cmd.Printf("Last updated %s ago\n", time.Since(time.Unix(spec.LastUpdated, 0)).Truncate(time.Second))
+				}
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "output format (text, json, yaml)")
+	cmd.Flags().StringVar(&cog, "cog", "", "only show invasions of cogs whose name contains this string")
+	cmd.Flags().StringVar(&department, "department", "", "only show invasions of this department (sellbot, cashbot, lawbot, bossbot)")
+	cmd.Flags().StringVar(&sortBy, "sort", "remaining", "sort by remaining cogs (most first), district, or cog")
+	return cmd
+}
@@ -3,8 +3,8 @@ package commands
 import (
 	"fmt"
 	"os"
+	"slices"
 	"sync"
-	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/jedib0t/go-pretty/v6/text"
@@ -18,131 +18,149 @@ import (
 // LaunchCmd represents the launch command
 func BuildLaunchCmd() *cobra.Command {
 	var skipUpdateCheck bool
+	var preferDistrict string
 	cmd := &cobra.Command{
 		Use:   "launch",
 		Short: "Launch the TTR engine",
-		PreRun: func(cmd *cobra.Command, args []string) {
-			go game.RunGLFW()
-		},
-		PostRun: func(cmd *cobra.Command, args []string) {
-			game.ShutdownGLFW()
-		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// check for updates in the background
-			client := api.NewClient()
-
-			status, err := client.Status(cmd.Context())
-			if err != nil {
-				return fmt.Errorf("failed to get game status: %w", err)
-			}
-			banner := status.Banner
-			if !status.Open {
-				if banner == "" {
-					banner = "(no details given)"
+			var pref *api.DistrictPreference
+			if preferDistrict != "" {
+				p, err := api.ParseDistrictPreference(preferDistrict)
+				if err != nil {
+					return err
 				}
-				cmd.Printf(text.Colors{text.FgRed}.Sprintf("Game may be closed: %s\n", banner))
-			} else if banner != "" {
-				cmd.Printf(text.Colors{text.Bold, text.FgYellow}.Sprintf("%s\n\n", banner))
-			}
-			doneUpdating := make(chan error, 1)
-			if skipUpdateCheck {
-				close(doneUpdating)
-			} else {
-				go func() {
-					defer close(doneUpdating)
-					if err := game.SyncGameData(cmd.Context(), client); err != nil {
-						doneUpdating <- err
-					}
-				}()
+				pref = &p
 			}
+			return runLaunch(cmd, launchOptions{
+				skipUpdateCheck: skipUpdateCheck,
+				preferDistrict:  pref,
+			})
+		},
+	}
 
-			// prompt for account
-			accounts := config.ListAccounts()
-			if len(accounts) == 0 {
-				return fmt.Errorf("no accounts found, run `ttr accounts add` to add one.")
-			}
+	cmd.Flags().BoolVar(&skipUpdateCheck, "skip-update-check", false, "Skip checking for updates")
+	cmd.Flags().StringVar(&preferDistrict, "prefer-district", "", "Recommend districts before launching (least-populated, invasion, or invasion:<cog or department>)")
+	return cmd
+}
 
-			var selected []string
-			if err := survey.AskOne(&survey.MultiSelect{
-				Message: "Select accounts:",
-				Options: accounts,
-			}, &selected); err != nil {
-				return err
+type launchOptions struct {
+	skipUpdateCheck bool
+	preferDistrict  *api.DistrictPreference
+}
+
+// setupMapRenderer returns the map renderer selected in the config, starting
+// GLFW if it shows maps in a window. The returned func stops GLFW again, and
+// must be called once the games have exited.
+func setupMapRenderer() (game.MapRenderer, func(), error) {
+	renderer, err := game.NewMapRenderer(config.MapRenderer(), game.MapRendererOptions{
+		Path: config.MapPath(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := renderer.(game.WindowRenderer); ok {
+		go game.RunGLFW()
+		return renderer, game.ShutdownGLFW, nil
+	}
+	return renderer, func() {}, nil
+}
+
+func runLaunch(cmd *cobra.Command, opts launchOptions) error {
+	// check for updates in the background
+	client := api.NewClient()
+
+	status, err := client.Status(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get game status: %w", err)
+	}
+	banner := status.Banner
+	if !status.Open {
+		if banner == "" {
+			banner = "(no details given)"
+		}
+		cmd.Printf(text.Colors{text.FgRed}.Sprintf("Game may be closed: %s\n", banner))
+	} else if banner != "" {
+		cmd.Printf(text.Colors{text.Bold, text.FgYellow}.Sprintf("%s\n\n", banner))
+	}
+	doneUpdating := make(chan error, 1)
+	if opts.preferDistrict != nil {
+		printDistrictRecommendation(cmd, client, *opts.preferDistrict)
+	}
+	// set before doneUpdating is closed
+	var gameUpdated bool
+	if opts.skipUpdateCheck {
+		close(doneUpdating)
+	} else {
+		go func() {
+			defer close(doneUpdating)
+			updated, err := game.SyncGameData(cmd.Context(), client)
+			if err != nil {
+				doneUpdating <- err
+				return
 			}
+			gameUpdated = updated
+		}()
+	}
 
-			var wg sync.WaitGroup
+	// prompt for account
+	accounts := config.ListAccounts()
+	if len(accounts) == 0 {
+		return fmt.Errorf("no accounts found, run `ttr accounts add` to add one.")
+	}
 
-			for _, account := range selected {
-				account := account
+	var selected []string
+	if err := survey.AskOne(&survey.MultiSelect{
+		Message: "Select accounts:",
+		Options: accounts,
+	}, &selected); err != nil {
+		return err
+	}
 
-				pw, err := auth.GetAccountPasswordOrPrompt(account)
-				if err != nil {
-					return err
-				}
+	integrations, err := setupIntegrations()
+	if err != nil {
+		return err
+	}
+	defer integrations.Close()
+	mapRenderer, stopRenderer, err := setupMapRenderer()
+	if err != nil {
+		return err
+	}
+	defer stopRenderer()
+	procOpts := append(slices.Clone(integrations.procOpts), game.WithMapRenderer(mapRenderer))
 
-				resp, err := client.Login(cmd.Context(), account, pw)
-				if err != nil {
-					return fmt.Errorf("login failed: %w", err)
-				}
-			RESPONSE:
-				for {
-					switch resp.Success {
-					case api.SuccessTrue:
-						break RESPONSE
-					case api.SuccessFalse:
-						return fmt.Errorf("login failed: %s", resp.Message)
-					case api.SuccessPartial:
-						var code string
-						if auth.HasTwoFactorAuthSecret(account) {
-							var err error
-							fmt.Printf("Generating two-factor authentication code for %s...\n", account)
-							code, err = auth.GenerateTwoFactorAuthCode(account)
-							if err != nil {
-								fmt.Fprintf(os.Stderr, "error generating two-factor authentication code: %s", err)
-								return err
-							}
-						} else {
-							if err := survey.AskOne(&survey.Password{
-								Message: "Enter a two-factor authentication code for " + account + ":",
-							}, &code); err != nil {
-								return err
-							}
-						}
-						resp, err = client.CompleteTwoFactorAuth(cmd.Context(), resp.ResponseToken, code)
-						if err != nil {
-							return fmt.Errorf("two-factor authentication failed: %w", err)
-						}
-					case api.SuccessDelayed:
-						if resp.ETA > 0 {
-							time.Sleep(1 * time.Second)
-						}
-						resp, err = client.RetryDelayedLogin(cmd.Context(), resp.QueueToken)
-						if err != nil {
-							return fmt.Errorf("failed to retry delayed login: %w", err)
-						}
-					}
-				}
+	var wg sync.WaitGroup
 
-				// wait for updates to finish
-				if err := <-doneUpdating; err != nil {
-					return fmt.Errorf("update failed: %w", err)
-				}
+	for _, account := range selected {
+		account := account
+
+		creds, err := auth.Login(cmd.Context(), client, account, auth.LoginOptions{
+			Interactive:          true,
+			RecoveryCodeFallback: true,
+		})
+		integrations.loggedIn(account, err)
+		if err != nil {
+			return err
+		}
 
-				wg.Add(1)
-				go func() {
-					defer wg.Done()
-					fmt.Printf("Running: %s\n", account)
-					if err := game.LaunchProcess(cmd.Context(), resp.LoginSuccessPayload); err != nil {
-						fmt.Fprintln(os.Stderr, err)
-					}
-					fmt.Printf("Exited: %s\n", account)
-				}()
+		// wait for updates to finish
+		if err := <-doneUpdating; err != nil {
+			return fmt.Errorf("update failed: %w", err)
+		}
+		if gameUpdated {
+			showUnreadReleaseNotes(cmd, client)
+			gameUpdated = false
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fmt.Printf("Running: %s\n", account)
+			if err := game.LaunchProcess(cmd.Context(), account, creds, procOpts...); err != nil {
+				fmt.Fprintln(os.Stderr, err)
 			}
-			wg.Wait()
-			return nil
-		},
+			fmt.Printf("Exited: %s\n", account)
+		}()
 	}
-
-	cmd.Flags().BoolVar(&skipUpdateCheck, "skip-update-check", false, "Skip checking for updates")
-	return cmd
+	wg.Wait()
+	return nil
 }
@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/kralicky/ttr/pkg/api"
+	"github.com/kralicky/ttr/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// width at which post bodies are wrapped
+const postWidth = 80
+
+func BuildNewsCmd() *cobra.Command {
+	var output string
+	var list bool
+	cmd := &cobra.Command{
+		Use:   "news [id]",
+		Short: "Show the latest news post, or a post by ID",
+		Args:  cobra.MaximumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if list && len(args) > 0 {
+				return fmt.Errorf("--list does not take a post ID")
+			}
+			return validateOutputFormat(output)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := api.NewClient()
+			if list {
+				posts, err := client.NewsList(cmd.Context())
+				if err != nil {
+					return err
+				}
+				return writeOutput(cmd.OutOrStdout(), output, posts, func() {
+					w := table.NewWriter()
+					w.SetStyle(table.StyleColoredDark)
+					w.AppendHeader(table.Row{"ID", "TITLE", "AUTHOR", "DATE"})
+					for _, p := range posts {
+						w.AppendRow(table.Row{p.PostId, p.Title, p.Author, p.Date})
+					}
+					cmd.Println(w.Render())
+				})
+			}
+
+			var post *api.NewsPost
+			if len(args) > 0 {
+				id, err := strconv.Atoi(args[0])
+				if err != nil {
+					return fmt.Errorf("invalid post ID %q", args[0])
+				}
+				post, err = client.NewsPost(cmd.Context(), id)
+				if err != nil {
+					return err
+				}
+			} else {
+				var err error
+				post, err = client.LatestNews(cmd.Context())
+				if err != nil {
+					return err
+				}
+			}
+			return writeOutput(cmd.OutOrStdout(), output, post, func() {
+				printPost(cmd, post.Title, fmt.Sprintf("%s | %s", post.Author, post.Date), post.Text())
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "output format (text, json, yaml)")
+	cmd.Flags().BoolVarP(&list, "list", "l", false, "list all news posts")
+	return cmd
+}
+
+func BuildReleaseNotesCmd() *cobra.Command {
+	var output string
+	var list bool
+	cmd := &cobra.Command{
+		Use:   "releasenotes [id]",
+		Short: "Show the latest release notes, or release notes by ID",
+		Args:  cobra.MaximumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if list && len(args) > 0 {
+				return fmt.Errorf("--list does not take a release notes ID")
+			}
+			return validateOutputFormat(output)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := api.NewClient()
+			if list {
+				notes, err := client.ReleaseNotes(cmd.Context())
+				if err != nil {
+					return err
+				}
+				return writeOutput(cmd.OutOrStdout(), output, notes, func() {
+					w := table.NewWriter()
+					w.SetStyle(table.StyleColoredDark)
+					w.AppendHeader(table.Row{"ID", "VERSION", "DATE"})
+					for _, n := range notes {
+						w.AppendRow(table.Row{n.NoteId, n.Slug, n.Date})
+					}
+					cmd.Println(w.Render())
+				})
+			}
+
+			var id int
+			if len(args) > 0 {
+				var err error
+				id, err = strconv.Atoi(args[0])
+				if err != nil {
+					return fmt.Errorf("invalid release notes ID %q", args[0])
+				}
+			} else {
+				notes, err := client.ReleaseNotes(cmd.Context())
+				if err != nil {
+					return err
+				}
+				if len(notes) == 0 {
+					return fmt.Errorf("no release notes found")
+				}
+				id = notes[0].NoteId
+			}
+			note, err := client.ReleaseNote(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+			return writeOutput(cmd.OutOrStdout(), output, note, func() {
+				printReleaseNote(cmd, note)
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "output format (text, json, yaml)")
+	cmd.Flags().BoolVarP(&list, "list", "l", false, "list all release notes")
+	return cmd
+}
+
+func printPost(cmd *cobra.Command, title, subtitle, body string) {
+	cmd.Println(text.Colors{text.Bold}.Sprint(title))
+	cmd.Println(text.Colors{text.Faint}.Sprint(subtitle))
+	cmd.Println()
+	cmd.Println(text.WrapSoft(body, postWidth))
+}
+
+func printReleaseNote(cmd *cobra.Command, note *api.ReleaseNote) {
+	printPost(cmd, "Release notes: "+note.Slug, note.Date, note.Text())
+}
+
+// showUnreadReleaseNotes prints any release notes newer than the last ones
+// shown, and records the latest as seen. The first time, only the latest
+// release notes are shown. Errors are printed rather than returned, since
+// this is only informational.
+func showUnreadReleaseNotes(cmd *cobra.Command, client api.InfoClient) {
+	notes, err := client.ReleaseNotes(cmd.Context())
+	if err != nil {
+		cmd.PrintErrln("failed to fetch release notes:", err)
+		return
+	}
+	if len(notes) == 0 {
+		return
+	}
+	lastSeen := config.LastSeenReleaseNote()
+	var unread []*api.ReleaseNote
+	for _, n := range notes {
+		if n.NoteId <= lastSeen || (lastSeen == 0 && len(unread) == 1) {
+			break
+		}
+		unread = append(unread, n)
+	}
+	if len(unread) == 0 {
+		return
+	}
+	// show the oldest unread notes first
+	for i := len(unread) - 1; i >= 0; i-- {
+		note, err := client.ReleaseNote(cmd.Context(), unread[i].NoteId)
+		if err != nil {
+			cmd.PrintErrln("failed to fetch release notes:", err)
+			return
+		}
+		printReleaseNote(cmd, note)
+		cmd.Println()
+	}
+	config.SetLastSeenReleaseNote(notes[0].NoteId)
+	if err := config.Save(); err != nil {
+		cmd.PrintErrln("failed to save config:", err)
+	}
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+func validateOutputFormat(format string) error {
+	switch format {
+	case outputText, outputJSON, outputYAML:
+		return nil
+	default:
+		return fmt.Errorf("invalid output format %q (expected text, json, or yaml)", format)
+	}
+}
+
+// writeOutput writes v to w in the given format. For the text format, the
+// text func is called instead.
+func writeOutput(w io.Writer, format string, v any, text func()) error {
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputYAML:
+		// round-trip through json so that the json field names are used
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var obj any
+		if err := yaml.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(obj); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		text()
+		return nil
+	}
+}
+
+// ExitError is returned by commands that need to exit with a specific status
+// code.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/kralicky/ttr/pkg/game"
+	"github.com/kralicky/ttr/pkg/report"
+	"github.com/spf13/cobra"
+)
+
+const outputCSV = "csv"
+
+func BuildReportCmd() *cobra.Command {
+	var output string
+	var last int
+	cmd := &cobra.Command{
+		Use:   "report [log files...]",
+		Short: "Show a timeline of game sessions from their log files",
+		Long: `Show a timeline of game sessions from their log files: the zones visited,
+Cog HQ runs, time spent in each playground, and disconnects.
+
+If no log files are given, the most recent logs written by the launcher are
+used (see --last). Zone durations are only available for logs written by
+launcher versions that add timestamps to the game logs.`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if output != outputCSV {
+				if err := validateOutputFormat(output); err != nil {
+					return fmt.Errorf("invalid output format %q (expected text, json, yaml, or csv)", output)
+				}
+			}
+			if last < 1 {
+				return errors.New("--last must be at least 1")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files := args
+			if len(files) == 0 {
+				var err error
+				files, err = recentLogFiles(last)
+				if err != nil {
+					return err
+				}
+			}
+			sessions := []*report.Session{}
+			for _, file := range files {
+				session, err := report.ReplayFile(file)
+				if err != nil {
+					return err
+				}
+				sessions = append(sessions, session)
+			}
+
+			if output == outputCSV {
+				return writeReportCSV(cmd.OutOrStdout(), sessions)
+			}
+			return writeOutput(cmd.OutOrStdout(), output, sessions, func() {
+				for i, s := range sessions {
+					if i > 0 {
+						cmd.Println()
+					}
+					printSession(cmd, s)
+				}
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "output format (text, json, yaml, csv)")
+	cmd.Flags().IntVar(&last, "last", 1, "number of recent log files to report on, if none are given")
+	return cmd
+}
+
+// recentLogFiles returns up to n of the most recent log files, oldest first.
+func recentLogFiles(n int) ([]string, error) {
+	dir, err := game.LogsDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "ttr-*.log"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no log files found in %s", dir)
+	}
+	slices.SortFunc(files, func(a, b string) int {
+		return report.LogFileTime(a).Compare(report.LogFileTime(b))
+	})
+	return files[max(0, len(files)-n):], nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.DateTime)
+}
+
+func formatDuration(s *report.Session, d report.Duration) string {
+	if !s.Timed {
+		return "-"
+	}
+	return time.Duration(d).Truncate(time.Second).String()
+}
+
+func printSession(cmd *cobra.Command, s *report.Session) {
+	cmd.Println(text.Colors{text.Bold}.Sprint(s.LogFile))
+	cmd.Printf("%s to %s (%s)\n", formatTime(s.Start), formatTime(s.End), time.Duration(s.Duration()).Truncate(time.Second))
+	if !s.Timed {
+		cmd.Println(text.Colors{text.FgYellow}.Sprint("This log has no timestamps; zone durations are not available."))
+	}
+
+	if len(s.Visits) > 0 {
+		w := table.NewWriter()
+		w.SetStyle(table.StyleColoredDark)
+		w.AppendHeader(table.Row{"TIME", "DURATION", "LOCATION", "PLAYGROUND"})
+		for _, v := range s.Visits {
+			w.AppendRow(table.Row{formatTime(v.Start), formatDuration(s, v.Duration), v.Location, v.Hood})
+		}
+		cmd.Println(w.Render())
+	}
+
+	if len(s.Runs) > 0 {
+		w := table.NewWriter()
+		w.SetStyle(table.StyleColoredDark)
+		w.AppendHeader(table.Row{"COG HQ RUN", "FLOORS", "TIME", "DURATION", "OUTCOME"})
+		for _, r := range s.Runs {
+			outcome := string(r.Outcome)
+			switch r.Outcome {
+			case report.Completed:
+				outcome = text.Colors{text.FgGreen}.Sprint(outcome)
+			case report.Disconnected:
+				outcome = text.Colors{text.FgRed}.Sprint(outcome)
+			}
+			w.AppendRow(table.Row{r.Name, r.FloorList(), formatTime(r.Start), formatDuration(s, r.Duration), outcome})
+		}
+		cmd.Println(w.Render())
+	}
+
+	if len(s.Hoods) > 0 {
+		w := table.NewWriter()
+		w.SetStyle(table.StyleColoredDark)
+		w.AppendHeader(table.Row{"PLAYGROUND", "TIME SPENT"})
+		for _, h := range s.Hoods {
+			w.AppendRow(table.Row{h.Hood, formatDuration(s, h.Duration)})
+		}
+		cmd.Println(w.Render())
+	}
+
+	for _, d := range s.Disconnects {
+		cmd.Println(text.Colors{text.FgRed}.Sprintf("Disconnected at %s: %s", formatTime(d.Time), d.Message))
+	}
+}
+
+// writeReportCSV writes one row per timeline entry (visit, run or
+// disconnect), in the order they appear in each session.
+func writeReportCSV(w io.Writer, sessions []*report.Session) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"session", "type", "start", "duration", "name", "playground", "details"})
+	rfc3339 := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	seconds := func(s *report.Session, d report.Duration) string {
+		if !s.Timed {
+			return ""
+		}
+		return fmt.Sprint(int64(time.Duration(d).Seconds()))
+	}
+	for _, s := range sessions {
+		cw.Write([]string{s.LogFile, "session", rfc3339(s.Start), fmt.Sprint(int64(time.Duration(s.Duration()).Seconds())), "", "", ""})
+		for _, v := range s.Visits {
+			cw.Write([]string{s.LogFile, "visit", rfc3339(v.Start), seconds(s, v.Duration), v.Location, v.Hood, v.Where})
+		}
+		for _, r := range s.Runs {
+			details := string(r.Outcome)
+			if len(r.Floors) > 0 {
+				details += "; floors " + strings.ReplaceAll(r.FloorList(), ", ", " ")
+			}
+			cw.Write([]string{s.LogFile, string(r.Kind), rfc3339(r.Start), seconds(s, r.Duration), r.Name, r.Hood, details})
+		}
+		for _, h := range s.Hoods {
+			cw.Write([]string{s.LogFile, "playground", "", seconds(s, h.Duration), h.Hood, h.Hood, ""})
+		}
+		for _, d := range s.Disconnects {
+			cw.Write([]string{s.LogFile, "disconnect", rfc3339(d.Time), "", "", "", d.Message})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
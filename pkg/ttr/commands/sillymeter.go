@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/kralicky/ttr/pkg/api"
+	"github.com/spf13/cobra"
+)
+
+func BuildSillyMeterCmd() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "sillymeter",
+		Short: "Show the Silly Meter and its rewards",
+		Args:  cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return validateOutputFormat(output)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := api.NewClient()
+			spec, err := client.SillyMeter(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return writeOutput(cmd.OutOrStdout(), output, spec, func() {
+				printSillyMeter(cmd, spec)
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "output format (text, json, yaml)")
+	return cmd
+}
+
+func untilString(t time.Time) string {
+	d := time.Until(t).Truncate(time.Minute)
+	if d <= 0 {
+		return "any moment now"
+	}
+	return fmt.Sprintf("in %s (%s)", strings.TrimSuffix(d.String(), "0s"), t.Local().Format(time.Kitchen))
+}
+
+func meterBar(percent float64, width int) string {
+	filled := int(percent / 100 * float64(width))
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
+}
+
+func printSillyMeter(cmd *cobra.Command, spec *api.SillyMeterSpec) {
+	switch spec.State {
+	case api.SillyMeterActive:
+		cmd.Printf("Silly Meter: %s %.1f%% (%d/%d)\n", meterBar(spec.Percent(), 30), spec.Percent(), spec.HP, api.SillyMeterMaxHP)
+	case api.SillyMeterReward:
+		name, desc, _ := spec.ActiveReward()
+		cmd.Println(text.Colors{text.Bold, text.FgGreen}.Sprint("Active reward: ", name))
+		if desc != "" {
+			cmd.Println(desc)
+		}
+		cmd.Println("Ends", untilString(spec.NextUpdate()))
+	case api.SillyMeterInactive:
+		cmd.Println("The Silly Meter is cooling down.")
+		cmd.Println("Starts filling again", untilString(spec.NextUpdate()))
+	default:
+		cmd.Printf("Silly Meter state: %s\n", spec.State)
+	}
+
+	choices := spec.RewardChoices()
+	if len(choices) == 0 {
+		return
+	}
+	if spec.State == api.SillyMeterActive {
+		cmd.Println("Reward choices:")
+	} else {
+		cmd.Println("Upcoming reward choices:")
+	}
+	w := table.NewWriter()
+	w.SetStyle(table.StyleColoredDark)
+	w.AppendHeader(table.Row{"REWARD", "POINTS", "DESCRIPTION"})
+	for _, c := range choices {
+		w.AppendRow(table.Row{c.Name, c.Points, c.Description})
+	}
+	cmd.Println(w.Render())
+}
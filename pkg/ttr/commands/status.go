@@ -1,6 +1,12 @@
 package commands
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
 	"time"
 
 	"github.com/jedib0t/go-pretty/v6/text"
@@ -8,36 +14,209 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// exitCodeClosed is the exit code of the status command when the game is
+// closed. 1 is reserved for other errors.
+const exitCodeClosed = 2
+
 func BuildStatusCmd() *cobra.Command {
+	var output string
+	var watch bool
+	var waitOpen bool
+	var launch bool
+	var interval time.Duration
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show game status details",
+		Long: `Show game status details.
+
+Exits with status 0 if the game is open, or 2 if it is closed.
+
+With --watch, the status is polled until interrupted, and printed each time
+the game opens or closes or the banner changes. With --wait-open, the status is
+polled until the game is open (e.g. after maintenance), and the game can then
+be launched with --launch.`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(output); err != nil {
+				return err
+			}
+			if watch && waitOpen {
+				return errors.New("--watch and --wait-open cannot be used together")
+			}
+			if launch && !waitOpen {
+				return errors.New("--launch requires --wait-open")
+			}
+			if interval < time.Second {
+				return errors.New("--interval must be at least 1s")
+			}
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client := api.NewClient()
 
+			switch {
+			case watch:
+				return watchStatus(cmd, client, output, interval)
+			case waitOpen:
+				if err := waitForOpen(cmd, client, output, interval); errors.Is(err, context.Canceled) {
+					return nil
+				} else if err != nil {
+					return err
+				}
+				if launch {
+					return runLaunch(cmd, launchOptions{})
+				}
+				return nil
+			}
+
 			status, err := client.Status(cmd.Context())
 			if err != nil {
 				return err
 			}
-			if status.Open {
-				cmd.Println(text.Colors{text.FgGreen}.Sprint("Game is open"))
-			} else {
-				cmd.Println(text.Colors{text.FgRed}.Sprint("Game is closed"))
-			}
-			if status.Banner != "" {
-				cmd.Println(text.Colors{text.Bold, text.FgYellow}.Sprint(status.Banner))
-			}
-			if status.LastCookieIssuedAt > 0 {
-				t := time.Unix(status.LastCookieIssuedAt, 0)
-				cmd.Printf("Last cookie issued at: %s (%s ago)\n", t.Local().Format(time.RFC3339), time.Since(t).Truncate(time.Second))
+			if err := writeOutput(cmd.OutOrStdout(), output, status, func() {
+				printStatus(cmd, status)
+			}); err != nil {
+				return err
 			}
-			if status.LastGameAuthAt > 0 {
-				t := time.Unix(status.LastGameAuthAt, 0)
-				cmd.Printf("Last game auth at:     %s (%s ago)\n", t.Local().Format(time.RFC3339), time.Since(t).Truncate(time.Second))
+			if !status.Open {
+				return &ExitError{Code: exitCodeClosed, Err: errors.New("game is closed")}
 			}
-
 			return nil
 		},
 	}
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "output format (text, json, yaml)")
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "keep polling, and print the status each time it changes")
+	cmd.Flags().BoolVar(&waitOpen, "wait-open", false, "wait until the game is open")
+	cmd.Flags().BoolVar(&launch, "launch", false, "launch the game once it is open (requires --wait-open)")
+	cmd.Flags().DurationVar(&interval, "interval", 30*time.Second, "how often to poll the status")
 	return cmd
 }
+
+func printStatus(cmd *cobra.Command, status api.StatusSpec) {
+	if status.Open {
+		cmd.Println(text.Colors{text.FgGreen}.Sprint("Game is open"))
+	} else {
+		cmd.Println(text.Colors{text.FgRed}.Sprint("Game is closed"))
+	}
+	if status.Banner != "" {
+		cmd.Println(text.Colors{text.Bold, text.FgYellow}.Sprint(status.Banner))
+	}
+	if status.LastCookieIssuedAt > 0 {
+		t := time.Unix(status.LastCookieIssuedAt, 0)
+		cmd.Printf("Last cookie issued at: %s (%s ago)\n", t.Local().Format(time.RFC3339), time.Since(t).Truncate(time.Second))
+	}
+	if status.LastGameAuthAt > 0 {
+		t := time.Unix(status.LastGameAuthAt, 0)
+		cmd.Printf("Last game auth at:     %s (%s ago)\n", t.Local().Format(time.RFC3339), time.Since(t).Truncate(time.Second))
+	}
+}
+
+// StatusChange describes a transition observed while watching the game status.
+type StatusChange struct {
+	Time     time.Time       `json:"time"`
+	Status   api.StatusSpec  `json:"status"`
+	Opened   bool            `json:"opened,omitempty"`
+	Closed   bool            `json:"closed,omitempty"`
+	Banner   bool            `json:"bannerChanged,omitempty"`
+	Previous *api.StatusSpec `json:"previous,omitempty"`
+}
+
+func diffStatus(prev *api.StatusSpec, cur api.StatusSpec) (StatusChange, bool) {
+	change := StatusChange{
+		Time:     time.Now(),
+		Status:   cur,
+		Previous: prev,
+	}
+	if prev == nil {
+		return change, true
+	}
+	change.Opened = !prev.Open && cur.Open
+	change.Closed = prev.Open && !cur.Open
+	change.Banner = prev.Banner != cur.Banner
+	return change, change.Opened || change.Closed || change.Banner
+}
+
+func printStatusChange(cmd *cobra.Command, output string, change StatusChange) error {
+	switch output {
+	case outputJSON:
+		// one object per line, so the output can be consumed as a stream
+		return json.NewEncoder(cmd.OutOrStdout()).Encode(change)
+	case outputYAML:
+		fmt.Fprintln(cmd.OutOrStdout(), "---")
+		return writeOutput(cmd.OutOrStdout(), output, change, nil)
+	}
+	ts := change.Time.Local().Format(time.TimeOnly)
+	switch {
+	case change.Opened:
+		cmd.Println(ts, text.Colors{text.FgGreen}.Sprint("Game is now open"))
+	case change.Closed:
+		cmd.Println(ts, text.Colors{text.FgRed}.Sprint("Game is now closed"))
+	case change.Previous == nil && change.Status.Open:
+		cmd.Println(ts, text.Colors{text.FgGreen}.Sprint("Game is open"))
+	case change.Previous == nil:
+		cmd.Println(ts, text.Colors{text.FgRed}.Sprint("Game is closed"))
+	}
+	if change.Banner || (change.Previous == nil && change.Status.Banner != "") {
+		if change.Status.Banner == "" {
+			cmd.Println(ts, "Banner cleared")
+		} else {
+			cmd.Println(ts, text.Colors{text.Bold, text.FgYellow}.Sprint(change.Status.Banner))
+		}
+	}
+	return nil
+}
+
+// pollStatus calls fn with each status change until fn returns false, the
+// user hits Ctrl+C, or the context is done. Errors while polling are printed
+// and retried.
+func pollStatus(ctx context.Context, cmd *cobra.Command, client api.Client, interval time.Duration, fn func(StatusChange) (bool, error)) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	var prev *api.StatusSpec
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		status, err := client.Status(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			cmd.PrintErrln("error fetching status:", err)
+		} else {
+			if change, changed := diffStatus(prev, status); changed {
+				cont, err := fn(change)
+				if err != nil || !cont {
+					return err
+				}
+			}
+			prev = &status
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func watchStatus(cmd *cobra.Command, client api.Client, output string, interval time.Duration) error {
+	err := pollStatus(cmd.Context(), cmd, client, interval, func(change StatusChange) (bool, error) {
+		return true, printStatusChange(cmd, output, change)
+	})
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+func waitForOpen(cmd *cobra.Command, client api.Client, output string, interval time.Duration) error {
+	return pollStatus(cmd.Context(), cmd, client, interval, func(change StatusChange) (bool, error) {
+		if err := printStatusChange(cmd, output, change); err != nil {
+			return false, err
+		}
+		if !change.Status.Open && change.Previous == nil && output == outputText {
+			cmd.Println("Waiting for the game to open...")
+		}
+		return !change.Status.Open, nil
+	})
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/kralicky/ttr/pkg/auth"
+	"github.com/kralicky/ttr/pkg/companion"
+	"github.com/kralicky/ttr/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+type toonRow struct {
+	companion.Engine
+	Info  *companion.Info `json:"info,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// companionAuthToken returns the stored companion app token, generating and
+// saving one the first time, so that players are only prompted once.
+func companionAuthToken() (string, error) {
+	token, err := auth.CompanionAuthToken()
+	if err == nil {
+		return token, nil
+	} else if !errors.Is(err, auth.ErrNotFound) {
+		return "", err
+	}
+	// tokens used to be kept in the config file
+	if token = config.CompanionAuthToken(); token == "" {
+		token = companion.NewAuthToken()
+	}
+	if err := auth.SetCompanionAuthToken(token); err != nil {
+		return "", err
+	}
+	if config.CompanionAuthToken() != "" {
+		config.SetCompanionAuthToken("")
+		return token, config.Save()
+	}
+	return token, nil
+}
+
+func BuildToonsCmd() *cobra.Command {
+	var output string
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:   "toons",
+		Short: "Show the toons logged in to running games",
+		Long: `Show the toons logged in to running games, using the companion app API.
+
+The first time, each game asks for permission in game before sharing its
+toon's details; requests wait until it is allowed or denied, up to --timeout.`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return validateOutputFormat(output)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token, err := companionAuthToken()
+			if err != nil {
+				return err
+			}
+			engines := companion.Discover(cmd.Context())
+			if len(engines) == 0 && output == outputText {
+				cmd.Println("No running games found.")
+				return nil
+			}
+			if output == outputText {
+				cmd.PrintErrln("Waiting for each game to allow access (check for a prompt in game)...")
+			}
+
+			ctx, ca := context.WithTimeout(cmd.Context(), timeout)
+			defer ca()
+			rows := make([]toonRow, len(engines))
+			var wg sync.WaitGroup
+			for i, e := range engines {
+				i, e := i, e
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					rows[i].Engine = e
+					info, err := companion.NewClient(e.Port, companion.WithAuthToken(token)).Info(ctx)
+					if err != nil {
+						rows[i].Error = err.Error()
+						return
+					}
+					rows[i].Info = info
+				}()
+			}
+			wg.Wait()
+
+			return writeOutput(cmd.OutOrStdout(), output, rows, func() {
+				w := table.NewWriter()
+				w.SetStyle(table.StyleColoredDark)
+				w.AppendHeader(table.Row{"ACCOUNT", "PORT", "TOON", "LAFF", "LOCATION", "DISTRICT"})
+				for _, r := range rows {
+					if r.Info == nil {
+						w.AppendRow(table.Row{r.Account, r.Port, "error: " + r.Error})
+						continue
+					}
+					location := r.Info.Location.Zone
+					if r.Info.Location.Neighborhood != "" && r.Info.Location.Neighborhood != location {
+						location += ", " + r.Info.Location.Neighborhood
+					}
+					w.AppendRow(table.Row{
+						r.Account,
+						r.Port,
+						r.Info.Toon.Name,
+						fmt.Sprintf("%d/%d", r.Info.Laff.Current, r.Info.Laff.Max),
+						location,
+						r.Info.Location.District,
+					})
+				}
+				cmd.Println(w.Render())
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", outputText, "output format (text, json, yaml)")
+	cmd.Flags().DurationVar(&timeout, "timeout", time.Minute, "how long to wait for each game to allow access")
+	return cmd
+}
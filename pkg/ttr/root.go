@@ -1,10 +1,12 @@
 package ttr
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/kralicky/ttr/pkg/auth"
 	"github.com/kralicky/ttr/pkg/config"
 	"github.com/kralicky/ttr/pkg/game"
 	"github.com/kralicky/ttr/pkg/ttr/commands"
@@ -19,6 +21,8 @@ func BuildRootCmd() *cobra.Command {
 		Use:          "ttr",
 		Short:        "TTR CLI Launcher",
 		SilenceUsage: true,
+		// errors are printed by Execute, so that ExitErrors can be silent
+		SilenceErrors: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			level, err := logrus.ParseLevel(logLevel)
 			if err != nil {
@@ -32,7 +36,25 @@ func BuildRootCmd() *cobra.Command {
 				os.Exit(1)
 			}
 			configFile := filepath.Join(dataDir, "cli-config.yaml")
-			return config.Load(configFile)
+			if err := config.Load(configFile); err != nil {
+				return err
+			}
+
+			vaultPath := config.SecretFilePath()
+			if vaultPath == "" {
+				vaultPath = filepath.Join(dataDir, "secrets.age")
+			}
+			store, err := auth.NewStore(config.SecretBackend(), auth.StoreOptions{
+				FilePath:   vaultPath,
+				PassPrefix: config.SecretPassPrefix(),
+				Command:    config.SecretCommand(),
+			})
+			if err != nil {
+				return err
+			}
+			auth.SetStore(store)
+			auth.SetClockSkew(config.ClockSkew())
+			return nil
 		},
 	}
 
@@ -40,8 +62,12 @@ func BuildRootCmd() *cobra.Command {
 	accountsCmd.AddCommand(commands.BuildAddCmd())
 	accountsCmd.AddCommand(commands.BuildListCmd())
 	accountsCmd.AddCommand(commands.BuildRmCmd())
+	accountsCmd.AddCommand(commands.BuildPruneCmd())
 	accountsCmd.AddCommand(commands.BuildEditCmd())
 	accountsCmd.AddCommand(commands.BuildTwoFactorAuthCmd())
+	accountsCmd.AddCommand(commands.BuildExportCmd())
+	accountsCmd.AddCommand(commands.BuildImportCmd())
+	accountsCmd.AddCommand(commands.BuildVerifyCmd())
 
 	rootCmd.AddCommand(accountsCmd)
 	rootCmd.AddCommand(commands.BuildLaunchCmd())
@@ -49,6 +75,15 @@ func BuildRootCmd() *cobra.Command {
 	rootCmd.AddCommand(commands.BuildDirCmd())
 	rootCmd.AddCommand(commands.BuildMultitoonCmd())
 	rootCmd.AddCommand(commands.BuildStatusCmd())
+	rootCmd.AddCommand(commands.BuildInvasionsCmd())
+	rootCmd.AddCommand(commands.BuildDistrictsCmd())
+	rootCmd.AddCommand(commands.BuildFieldOfficesCmd())
+	rootCmd.AddCommand(commands.BuildNewsCmd())
+	rootCmd.AddCommand(commands.BuildReleaseNotesCmd())
+	rootCmd.AddCommand(commands.BuildSillyMeterCmd())
+	rootCmd.AddCommand(commands.BuildDashboardCmd())
+	rootCmd.AddCommand(commands.BuildToonsCmd())
+	rootCmd.AddCommand(commands.BuildReportCmd())
 	//+cobra:subcommands
 
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Log level (debug, info, warn, error)")
@@ -59,6 +94,11 @@ func BuildRootCmd() *cobra.Command {
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	if err := BuildRootCmd().Execute(); err != nil {
+		var exitErr *commands.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
+		fmt.Fprintln(os.Stderr, "Error:", err)
 		os.Exit(1)
 	}
 }
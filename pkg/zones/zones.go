@@ -0,0 +1,152 @@
+// Package zones maps Toontown zone IDs to their names.
+package zones
+
+import (
+	_ "embed"
+	"encoding/json"
+	"slices"
+)
+
+// Playground zone IDs. Street zone IDs are the playground ID plus 100, 200 or
+// 300, and the zones within a street (e.g. buildings) are numbered from there.
+const (
+	DonaldsDock       = 1000
+	ToontownCentral   = 2000
+	TheBrrrgh         = 3000
+	MinniesMelodyland = 4000
+	DaisyGardens      = 5000
+	AcornAcres        = 6000
+	GoofySpeedway     = 8000
+	DonaldsDreamland  = 9000
+	BossbotHQ         = 10000
+	SellbotHQ         = 11000
+	CashbotHQ         = 12000
+	LawbotHQ          = 13000
+	Estate            = 16000
+)
+
+type Kind string
+
+const (
+	KindHood     Kind = "hood"
+	KindStreet   Kind = "street"
+	KindFacility Kind = "facility"
+)
+
+type Zone struct {
+	Id   int64
+	Name string
+	Kind Kind
+	// ID of the playground containing the zone. For playgrounds, this is the
+	// same as Id.
+	HoodId int64
+}
+
+//go:embed zones.json
+var zonesJson []byte
+
+type hoodEntry struct {
+	Id         int64       `json:"id"`
+	Name       string      `json:"name"`
+	Streets    []zoneEntry `json:"streets"`
+	Facilities []zoneEntry `json:"facilities"`
+}
+
+type zoneEntry struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+var (
+	database = map[int64]Zone{}
+	hoodIds  []int64
+)
+
+func init() {
+	var hoods []hoodEntry
+	if err := json.Unmarshal(zonesJson, &hoods); err != nil {
+		panic("invalid zone database: " + err.Error())
+	}
+	for _, h := range hoods {
+		hoodIds = append(hoodIds, h.Id)
+		database[h.Id] = Zone{Id: h.Id, Name: h.Name, Kind: KindHood, HoodId: h.Id}
+		for _, s := range h.Streets {
+			database[s.Id] = Zone{Id: s.Id, Name: s.Name, Kind: KindStreet, HoodId: h.Id}
+		}
+		for _, f := range h.Facilities {
+			database[f.Id] = Zone{Id: f.Id, Name: f.Name, Kind: KindFacility, HoodId: h.Id}
+		}
+	}
+	slices.Sort(hoodIds)
+}
+
+// Hoods returns all known playgrounds, ordered by ID.
+func Hoods() []Zone {
+	hoods := make([]Zone, len(hoodIds))
+	for i, id := range hoodIds {
+		hoods[i] = database[id]
+	}
+	return hoods
+}
+
+// Lookup returns the most specific known zone containing the given zone ID:
+// a Cog HQ facility, a street, or a playground.
+func Lookup(zoneId int64) (Zone, bool) {
+	if z, ok := database[zoneId]; ok {
+		return z, true
+	}
+	if z, ok := database[zoneId-zoneId%100]; ok && z.Kind == KindStreet {
+		return z, true
+	}
+	if z, ok := database[HoodId(zoneId)]; ok {
+		return z, true
+	}
+	return Zone{}, false
+}
+
+// HoodId returns the ID of the playground (hood) containing the zone.
+func HoodId(zoneId int64) int64 {
+	return zoneId - zoneId%1000
+}
+
+// StreetId returns the ID of the street containing the zone, or 0 if the zone
+// is not on a street.
+func StreetId(zoneId int64) int64 {
+	id := zoneId - zoneId%100
+	if z, ok := database[id]; ok && z.Kind == KindStreet {
+		return id
+	}
+	return 0
+}
+
+// HoodName returns the name of the playground containing the zone, or "" if
+// it is not known.
+func HoodName(zoneId int64) string {
+	if z, ok := database[HoodId(zoneId)]; ok {
+		return z.Name
+	}
+	return ""
+}
+
+// StreetName returns the name of the street containing the zone, or "" if the
+// zone is not on a known street.
+func StreetName(zoneId int64) string {
+	if id := StreetId(zoneId); id != 0 {
+		return database[id].Name
+	}
+	return ""
+}
+
+// Name returns a human readable name for the zone, e.g. "Walrus Way, The
+// Brrrgh". If the zone is not known, "" is returned.
+func Name(zoneId int64) string {
+	z, ok := Lookup(zoneId)
+	switch {
+	case !ok:
+		return ""
+	case z.Kind == KindHood:
+		return z.Name
+	default:
+		return z.Name + ", " + HoodName(zoneId)
+	}
+}
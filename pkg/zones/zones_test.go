@@ -0,0 +1,87 @@
+package zones_test
+
+import (
+	"testing"
+
+	"github.com/kralicky/ttr/pkg/zones"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHoods(t *testing.T) {
+	cases := []struct {
+		hood    int64
+		name    string
+		streets map[int64]string
+	}{
+		{zones.DonaldsDock, "Donald's Dock", map[int64]string{1100: "Barnacle Boulevard", 1200: "Seaweed Street", 1300: "Lighthouse Lane"}},
+		{zones.ToontownCentral, "Toontown Central", map[int64]string{2100: "Silly Street", 2200: "Loopy Lane", 2300: "Punchline Place"}},
+		{zones.TheBrrrgh, "The Brrrgh", map[int64]string{3100: "Walrus Way", 3200: "Sleet Street", 3300: "Polar Place"}},
+		{zones.MinniesMelodyland, "Minnie's Melodyland", map[int64]string{4100: "Alto Avenue", 4200: "Baritone Boulevard", 4300: "Tenor Terrace"}},
+		{zones.DaisyGardens, "Daisy Gardens", map[int64]string{5100: "Elm Street", 5200: "Maple Street", 5300: "Oak Street"}},
+		{zones.AcornAcres, "Chip 'n Dale's Acorn Acres", nil},
+		{zones.GoofySpeedway, "Goofy Speedway", nil},
+		{zones.DonaldsDreamland, "Donald's Dreamland", map[int64]string{9100: "Lullaby Lane", 9200: "Pajama Place"}},
+		{zones.BossbotHQ, "Bossbot HQ", nil},
+		{zones.SellbotHQ, "Sellbot HQ", nil},
+		{zones.CashbotHQ, "Cashbot HQ", nil},
+		{zones.LawbotHQ, "Lawbot HQ", nil},
+		{zones.Estate, "Estate", nil},
+	}
+	var ids []int64
+	for _, c := range cases {
+		ids = append(ids, c.hood)
+		t.Run(c.name, func(t *testing.T) {
+			z, ok := zones.Lookup(c.hood)
+			require.True(t, ok)
+			assert.Equal(t, zones.Zone{Id: c.hood, Name: c.name, Kind: zones.KindHood, HoodId: c.hood}, z)
+			assert.Equal(t, c.name, zones.Name(c.hood))
+			assert.Empty(t, zones.StreetName(c.hood))
+
+			for id, street := range c.streets {
+				// a zone within the street
+				z, ok := zones.Lookup(id + 35)
+				require.True(t, ok)
+				assert.Equal(t, zones.Zone{Id: id, Name: street, Kind: zones.KindStreet, HoodId: c.hood}, z)
+				assert.Equal(t, street+", "+c.name, zones.Name(id+35))
+				assert.Equal(t, c.name, zones.HoodName(id+35))
+			}
+		})
+	}
+
+	var known []int64
+	for _, h := range zones.Hoods() {
+		known = append(known, h.Id)
+	}
+	assert.Equal(t, ids, known, "every hood in the database should be tested")
+}
+
+func TestFacilities(t *testing.T) {
+	cases := map[int64]string{
+		10500: "The Front Three",
+		10700: "The Back Nine",
+		11500: "Sellbot Factory",
+		12500: "Coin Mint",
+		12600: "Dollar Mint",
+		12700: "Bullion Mint",
+		13300: "Office A",
+		13600: "Office D",
+	}
+	for id, name := range cases {
+		z, ok := zones.Lookup(id)
+		require.True(t, ok)
+		assert.Equal(t, zones.KindFacility, z.Kind)
+		assert.Equal(t, name, z.Name)
+		assert.Equal(t, zones.HoodId(id), z.HoodId)
+	}
+	assert.Equal(t, "Bullion Mint, Cashbot HQ", zones.Name(12700))
+}
+
+func TestUnknown(t *testing.T) {
+	_, ok := zones.Lookup(7000)
+	assert.False(t, ok)
+	assert.Empty(t, zones.Name(7100))
+	// interiors are not streets
+	assert.Equal(t, "Toontown Central", zones.Name(2601))
+	assert.Zero(t, zones.StreetId(2601))
+}